@@ -127,15 +127,27 @@ func TestEnvironments(t *testing.T) {
 		{
 			name:    "e environments info",
 			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "info"},
-			want:    []string{"Environment info:\n Name: " + time.Now().Format("060102")},
+			want:    []string{"Environment: " + time.Now().Format("060102"), "MODULE"},
 			wantErr: false,
 		},
 		{
 			name:    "e environments list",
 			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "list"},
-			want:    []string{fmt.Sprintf(") | %s-", time.Now().Format("060102"))},
+			want:    []string{"CURRENT", fmt.Sprintf("*        %s-", time.Now().Format("060102"))},
 			wantErr: false,
 		},
+		{
+			name:    "e environments list json",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "list", "--output", "json"},
+			want:    []string{fmt.Sprintf("\"name\": \"%s-", time.Now().Format("060102")), "\"current\": true"},
+			wantErr: false,
+		},
+		{
+			name:    "e environments list incorrect output",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "list", "--output", "xml"},
+			want:    []string{"unknown output format \\\"xml\\\" (supported: table, json, yaml)"},
+			wantErr: true,
+		},
 		{
 			name:    "e environments new",
 			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "new", "t1", "--logLevel", "debug"},
@@ -149,16 +161,66 @@ func TestEnvironments(t *testing.T) {
 			additionalEnvs: map[string]string{"69a1f007-ab54-4c5d-8fe3-8568ce319c61": "second-env"},
 			wantErr:        false,
 		},
+		{
+			name:           "e environments use by name",
+			args:           []string{"--configDir", util.UsedConfigurationDirectory, "environments", "use", "fourth-env", "--logLevel", "debug"},
+			want:           []string{"Chosen environment UUID is 5d0c2f7e-2b8e-4f0a-9c57-1d2e3f4a5b6c"},
+			additionalEnvs: map[string]string{"5d0c2f7e-2b8e-4f0a-9c57-1d2e3f4a5b6c": "fourth-env"},
+			wantErr:        false,
+		},
+		{
+			name:    "e environments use by id prefix",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "use", "69a1f0", "--logLevel", "debug"},
+			want:    []string{"Chosen environment UUID is 69a1f007-ab54-4c5d-8fe3-8568ce319c61"},
+			wantErr: false,
+		},
+		{
+			name:    "e environments new duplicated name",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "new", "fourth-env"},
+			want:    []string{"environment with name fourth-env already exists"},
+			wantErr: true,
+		},
 		{
 			name:    "e environments use incorrect",
 			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "use", "something-incorrect"},
-			want:    []string{"panic: uuid: Parse(something-incorrect): invalid UUID length: 19"},
+			want:    []string{"environment something-incorrect not found"},
 			wantErr: true,
 		},
 		{
 			name:    "e environments use unknown",
 			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "use", "8934387b-0c9f-42d2-a3c1-6acac763dd5a"},
-			want:    []string{"expected environment 8934387b-0c9f-42d2-a3c1-6acac763dd5a not found"},
+			want:    []string{"environment 8934387b-0c9f-42d2-a3c1-6acac763dd5a not found"},
+			wantErr: true,
+		},
+		{
+			name:           "e environments rename",
+			args:           []string{"--configDir", util.UsedConfigurationDirectory, "environments", "rename", "third-env", "renamed-env", "--logLevel", "debug"},
+			want:           []string{"was renamed from third-env to renamed-env"},
+			additionalEnvs: map[string]string{"0c6c6d7a-1fc2-4c6e-b1a6-a4cfd2e4f0b1": "third-env"},
+			wantErr:        false,
+		},
+		{
+			name:    "e environments clone",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "clone", "renamed-env", "cloned-env", "--logLevel", "debug"},
+			want:    []string{"Environment renamed-env was cloned into cloned-env with id "},
+			wantErr: false,
+		},
+		{
+			name:    "e environments delete",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "delete", "cloned-env", "--logLevel", "debug"},
+			want:    []string{"was deleted"},
+			wantErr: false,
+		},
+		{
+			name:    "e environments delete unknown",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "delete", "cloned-env"},
+			want:    []string{"environment cloned-env not found"},
+			wantErr: true,
+		},
+		{
+			name:    "e environments delete current",
+			args:    []string{"--configDir", util.UsedConfigurationDirectory, "environments", "delete", "69a1f007-ab54-4c5d-8fe3-8568ce319c61"},
+			want:    []string{"is currently selected, use force flag to delete it"},
 			wantErr: true,
 		},
 		{
@@ -254,154 +316,329 @@ func TestHelp(t *testing.T) {
 			name:            "e --help",
 			args:            []string{"--help"},
 			wantSubcommands: []string{"az", "environments", "help", "module", "repos", "ssh"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e az --help",
 			args:            []string{"az", "--help"},
 			wantSubcommands: []string{"sp"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e az sp --help",
 			args:            []string{"az", "sp", "--help"},
 			wantSubcommands: []string{"create"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e az sp create --help",
 			args:            []string{"az", "sp", "create", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel", "name", "subscriptionID", "tenantID"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "name", "subscriptionID", "tenantID"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments --help",
 			args:            []string{"environments", "--help"},
-			wantSubcommands: []string{"export", "import", "info", "list", "new", "run", "use"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantSubcommands: []string{"apply", "clone", "delete", "export", "import", "info", "list", "new", "outputs", "rename", "run", "runs", "shell", "snapshot", "use"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments apply --help",
+			args:            []string{"environments", "apply", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "reverse"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments clone --help",
+			args:            []string{"environments", "clone", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments delete --help",
+			args:            []string{"environments", "delete", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "force"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments export --help",
 			args:            []string{"environments", "export", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel", "destination", "id"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "destination", "encrypt", "excludeSecrets", "id", "passphrase", "recipient"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments import --help",
 			args:            []string{"environments", "import", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel", "from"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "asNew", "from", "identity", "name", "overwrite", "passphrase"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments info --help",
 			args:            []string{"environments", "info", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments list --help",
 			args:            []string{"environments", "list", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments new --help",
 			args:            []string{"environments", "new", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel", "name"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "name"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments outputs --help",
+			args:            []string{"environments", "outputs", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments rename --help",
+			args:            []string{"environments", "rename", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments run --help",
 			args:            []string{"environments", "run", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "env", "interactive", "timeout", "tty"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments runs --help",
+			args:            []string{"environments", "runs", "--help"},
+			wantSubcommands: []string{"list", "show"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments runs list --help",
+			args:            []string{"environments", "runs", "list", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments runs show --help",
+			args:            []string{"environments", "runs", "show", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments shell --help",
+			args:            []string{"environments", "shell", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "shell"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments snapshot --help",
+			args:            []string{"environments", "snapshot", "--help"},
+			wantSubcommands: []string{"auto", "create", "delete", "list", "restore"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments snapshot auto --help",
+			args:            []string{"environments", "snapshot", "auto", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments snapshot create --help",
+			args:            []string{"environments", "snapshot", "create", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "description", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments snapshot delete --help",
+			args:            []string{"environments", "snapshot", "delete", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments snapshot list --help",
+			args:            []string{"environments", "snapshot", "list", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e environments snapshot restore --help",
+			args:            []string{"environments", "snapshot", "restore", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e environments use --help",
 			args:            []string{"environments", "use", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e module --help",
 			args:            []string{"module", "--help"},
-			wantSubcommands: []string{"info", "install", "search"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantSubcommands: []string{"info", "install", "search", "uninstall", "upgrade", "verify"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e module info --help",
 			args:            []string{"module", "info", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e module install --help",
 			args:            []string{"module", "install", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e module search --help",
 			args:            []string{"module", "search", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "registry", "repository", "tag", "type"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e module uninstall --help",
+			args:            []string{"module", "uninstall", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e module upgrade --help",
+			args:            []string{"module", "upgrade", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "force", "migrateMounts", "repository", "version"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e module verify --help",
+			args:            []string{"module", "verify", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e repos --help",
 			args:            []string{"repos", "--help"},
-			wantSubcommands: []string{"install", "list"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantSubcommands: []string{"install", "list", "remove", "trust", "update", "validate"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e repos install --help",
 			args:            []string{"repos", "install", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel", "branch", "force"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "allowUnsigned", "branch", "force"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e repos list --help",
 			args:            []string{"repos", "list", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e repos remove --help",
+			args:            []string{"repos", "remove", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e repos update --help",
+			args:            []string{"repos", "update", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output", "all", "allowUnsigned"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e repos trust --help",
+			args:            []string{"repos", "trust", "--help"},
+			wantSubcommands: []string{"add", "list", "remove"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e repos trust add --help",
+			args:            []string{"repos", "trust", "add", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e repos trust list --help",
+			args:            []string{"repos", "trust", "list", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e repos trust remove --help",
+			args:            []string{"repos", "trust", "remove", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
+			wantOutput:      []string{},
+		},
+		{
+			name:            "e repos validate --help",
+			args:            []string{"repos", "validate", "--help"},
+			wantSubcommands: []string{},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e ssh --help",
 			args:            []string{"ssh", "--help"},
 			wantSubcommands: []string{"keygen"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e ssh keygen --help",
 			args:            []string{"ssh", "keygen", "--help"},
 			wantSubcommands: []string{"create"},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
 			name:            "e ssh keygen create --help",
 			args:            []string{"ssh", "keygen", "create", "--help"},
 			wantSubcommands: []string{},
-			wantFlags:       []string{"configDir", "help", "logLevel"},
+			wantFlags:       []string{"configDir", "help", "logLevel", "output"},
 			wantOutput:      []string{},
 		},
 		{
@@ -472,9 +709,16 @@ components:
               TF_LOG: WARN
 `),
 			},
-			want:    []string{"Version: 0.1.0", "Image: docker.io/hashicorp/terraform:0.12.28"},
+			want:    []string{"Version:     0.1.0", "Image:       docker.io/hashicorp/terraform:0.12.28", "init     initializes terraform in local directory"},
 			wantErr: false,
 		},
+		{
+			name:     "e module info not existing",
+			args:     []string{"--configDir", util.UsedConfigurationDirectory, "module", "info", "user/repo:version"},
+			mockRepo: nil,
+			want:     []string{"module not found: user/repo:version"},
+			wantErr:  true,
+		},
 		{
 			name: "e module install",
 			args: []string{"--configDir", util.UsedConfigurationDirectory, "module", "install", "example-repo/c1:0.1.0"},
@@ -528,7 +772,7 @@ components:
               TF_LOG: WARN
 `),
 			},
-			want:    []string{"example-repo/c1:0.1.0"},
+			want:    []string{"example-repo/c1  0.1.0"},
 			wantErr: false,
 		},
 		{
@@ -540,7 +784,7 @@ components:
 		},
 		{
 			name:     "e module install incorrect format 2",
-			args:     []string{"--configDir", util.UsedConfigurationDirectory, "module", "install", "user/repo"},
+			args:     []string{"--configDir", util.UsedConfigurationDirectory, "module", "install", "user/repo:version/incorrect"},
 			mockRepo: nil,
 			want:     []string{"Error: module name argument incorrectly formatted"},
 			wantErr:  false,
@@ -600,14 +844,19 @@ func TestRepos(t *testing.T) {
 		args []string
 		want []string
 	}{
+		{
+			name: "e repos install default",
+			args: []string{"--configDir", util.UsedConfigurationDirectory, "repos", "install", "epiphany-platform/modules", "--allowUnsigned", "--logLevel", "debug"},
+			want: []string{"will try to install epiphany-platform/modules"},
+		},
 		{
 			name: "e repos list",
 			args: []string{"--configDir", util.UsedConfigurationDirectory, "repos", "list"},
-			want: []string{"Module: terraform:0.1.0"},
+			want: []string{"REPOSITORY", "terraform"},
 		},
 		{
 			name: "e repos install",
-			args: []string{"--configDir", util.UsedConfigurationDirectory, "repos", "install", "mkyc/my-epiphany-repo", "--logLevel", "debug"},
+			args: []string{"--configDir", util.UsedConfigurationDirectory, "repos", "install", "mkyc/my-epiphany-repo", "--allowUnsigned", "--logLevel", "debug"},
 			want: []string{"will try to install mkyc/my-epiphany-repo"},
 		},
 		{
@@ -615,6 +864,16 @@ func TestRepos(t *testing.T) {
 			args: []string{"--configDir", util.UsedConfigurationDirectory, "repos", "install", "not-existing-user/not-existing-repo", "--logLevel", "trace"},
 			want: []string{"repository https://raw.githubusercontent.com/not-existing-user/not-existing-repo/HEAD/v1.yaml not found"},
 		},
+		{
+			name: "e repos validate",
+			args: []string{"--configDir", util.UsedConfigurationDirectory, "repos", "validate", path.Join("docs", "example-repository-v1.yaml")},
+			want: []string{"example-repository-v1.yaml is valid repository"},
+		},
+		{
+			name: "e repos trust list",
+			args: []string{"--configDir", util.UsedConfigurationDirectory, "repos", "trust", "list"},
+			want: []string{"NAME", "FINGERPRINT"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
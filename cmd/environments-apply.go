@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/processor"
+
+	"github.com/spf13/cobra"
+)
+
+const destroyCommand = "destroy"
+
+var applyReverse bool
+
+// envApplyCmd represents the apply command
+var envApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Runs command of all installed components in dependency order",
+	Long: `"apply" command runs named command (by default "apply") of all components installed in 
+currently selected environment. Components run after components they require and outputs are 
+collected after each run, so components can use outputs of components they require. Command 
+"destroy" (or any command with "--reverse" flag) runs in reverse order. Components not providing 
+command are skipped and e stops on first failure. If automatic snapshots are enabled, single 
+snapshot is taken before the first component runs.`,
+	Example: `e environments apply
+e environments apply destroy
+e environments apply cleanup --reverse`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return errors.New("there should be at most one positional argument with command name")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments apply called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		command := "apply"
+		if len(args) == 1 {
+			command = args[0]
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+		if currentEnvironment.AutoSnapshot {
+			s, err := currentEnvironment.CreateSnapshot(fmt.Sprintf("before apply %s", command), true)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("creating automatic snapshot failed")
+			}
+			logger.Info().Msgf("created automatic snapshot %d", s.Version)
+		}
+		err := currentEnvironment.RunAll(ctx, command, applyReverse || command == destroyCommand, func(cv *environment.InstalledComponentVersion) func(string) (string, error) {
+			return processor.TemplateProcessor(config, currentEnvironment, cv)
+		}, environment.RunOptions{})
+		var exitErr *environment.ExitError
+		if errors.As(err, &exitErr) {
+			logger.Error().Err(err).Msgf("applying %s failed", command)
+			os.Exit(int(exitErr.Code))
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("applying %s failed", command)
+		}
+		logger.Info().Msgf("applying %s finished", command)
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envApplyCmd)
+
+	envApplyCmd.Flags().BoolVar(&applyReverse, "reverse", false, "run components in reverse dependency order")
+}
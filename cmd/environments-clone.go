@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/spf13/cobra"
+)
+
+// envCloneCmd represents the clone command
+var envCloneCmd = &cobra.Command{
+	Use:   "clone",
+	Short: "Copies environment into new one",
+	Long: `"clone" command copies environment referenced by its id or name (with installed 
+components, their mounts, shared directory and runs history) into new environment with 
+newly generated id. Name of the clone is optional second argument, "<name>-clone" is used 
+if it is not provided. Selected environment is not changed.`,
+	Example: `e environments clone staging
+e environments clone staging production`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return errors.New("'clone' command gets id or name of environment to clone and optional name of the clone")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments clone called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		e, err := environment.Resolve(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting environment failed")
+		}
+		name := e.Name + "-clone"
+		if len(args) == 2 {
+			name = args[1]
+		}
+		clone, err := e.Clone(name)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("cloning environment %s failed", e.Uuid.String())
+		}
+		logger.Info().Msgf("Environment %s was cloned into %s with id %s", e.Name, clone.Name, clone.Uuid.String())
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envCloneCmd)
+}
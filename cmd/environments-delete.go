@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+var deleteForce bool
+
+// envDeleteCmd represents the delete command
+var envDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes environment",
+	Long: `"delete" command removes environment directory together with its snapshots and 
+temporary files. Environment can be referenced by its id or name. Currently selected 
+environment is deleted only with "--force", another environment is selected then 
+(or new one is created on next command if there is none left).`,
+	Example: `e environments delete 69a1f007-ab54-4c5d-8fe3-8568ce319c61
+e environments delete --force staging`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("'delete' command gets 1 argument with id or name of environment to delete")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments delete called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		e, err := environment.Resolve(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting environment failed")
+		}
+		current := e.Uuid == config.CurrentEnvironment
+		if current && !deleteForce {
+			logger.Fatal().Msgf("environment %s (%s) is currently selected, use force flag to delete it", e.Name, e.Uuid.String())
+		}
+		err = e.Delete()
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("deleting environment %s failed", e.Uuid.String())
+		}
+		logger.Info().Msgf("Environment %s (%s) was deleted", e.Name, e.Uuid.String())
+		if !current {
+			return
+		}
+
+		environments, err := environment.GetAll()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting environments failed")
+		}
+		config.CurrentEnvironment = uuid.Nil
+		if len(environments) > 0 {
+			config.CurrentEnvironment = environments[0].Uuid
+			logger.Info().Msgf("Switched to environment %s (%s)", environments[0].Name, environments[0].Uuid.String())
+		}
+		err = config.Save()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("saving config failed")
+		}
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envDeleteCmd)
+
+	envDeleteCmd.Flags().BoolVar(&deleteForce, "force", false, "delete environment even if it is currently selected")
+}
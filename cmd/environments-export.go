@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"os"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/promptui"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
@@ -12,8 +14,12 @@ import (
 )
 
 var (
-	envIdStr string
-	dstDir   string
+	envIdStr         string
+	dstDir           string
+	exportEncrypt    bool
+	exportPassphrase string
+	exportRecipients []string
+	excludeSecrets   bool
 )
 
 // envExportCmd represents envs export command
@@ -23,9 +29,18 @@ var envExportCmd = &cobra.Command{
 	Short:      "Exports an environment as a zip archive",
 	Long: `"export" command allows exporting any environment 
 as a zip archive into the specified directory 
-or into the current working directory by default`,
+or into the current working directory by default. 
+Archive contains private keys and all files written by components (e.g. Terraform state). 
+Use "--encrypt" to encrypt archive (age format) with passphrase (prompted for if "--passphrase" is not 
+provided) or with "--recipient" SSH public keys (ssh-ed25519 or ssh-rsa), passphrase and recipients 
+cannot be combined. Use "--excludeSecrets" to omit private keys and secret files declared by 
+components from archive.`,
 	Example: `Export current environment into current working directory: e environments export
-Export environment into home directory: e environments export --id ba03a2ba-8fa0-4c15-ac07-894af3dbb364 --destination ~`,
+Export environment into home directory: e environments export --id ba03a2ba-8fa0-4c15-ac07-894af3dbb364 --destination ~
+Export environment by name: e environments export --id staging
+Export encrypted with passphrase: e environments export --encrypt
+Export encrypted for SSH key: e environments export --recipient ~/.ssh/id_ed25519.pub
+Export without secrets: e environments export --excludeSecrets`,
 
 	PreRun: func(cmd *cobra.Command, args []string) {
 		logger.Debug().Msg("environments export called")
@@ -37,6 +52,10 @@ Export environment into home directory: e environments export --id ba03a2ba-8fa0
 
 		envIdStr = viper.GetString("id")
 		dstDir = viper.GetString("destination")
+		exportEncrypt = viper.GetBool("encrypt")
+		exportPassphrase = viper.GetString("passphrase")
+		exportRecipients = viper.GetStringSlice("recipient")
+		excludeSecrets = viper.GetBool("excludeSecrets")
 	},
 
 	Run: func(cmd *cobra.Command, args []string) {
@@ -61,14 +80,12 @@ Export environment into home directory: e environments export --id ba03a2ba-8fa0
 				envId = config.CurrentEnvironment
 			}
 		} else {
-			envId = uuid.MustParse(envIdStr)
-			// Check if passed environment id is valid
-			exists, err := environment.IsExisting(envId)
+			// Passed environment can be referenced by id, id prefix or name
+			e, err := environment.Resolve(envIdStr)
 			if err != nil {
-				logger.Fatal().Err(err).Msgf("Environment existence check failed (environment id: %s)", envId.String())
-			} else if !exists {
-				logger.Fatal().Msgf("Environment not found (environment id: %s)", envId.String())
+				logger.Fatal().Err(err).Msgf("Environment not found (environment id: %s)", envIdStr)
 			}
+			envId = e.Uuid
 		}
 
 		// Export an environment
@@ -77,10 +94,29 @@ Export environment into home directory: e environments export --id ba03a2ba-8fa0
 			logger.Fatal().Err(err).Msgf("Unable to get an environment by id (environment id: %s)", envId.String())
 		}
 
-		err = env.Export(dstDir)
+		options := environment.ExportOptions{
+			Passphrase:     exportPassphrase,
+			ExcludeSecrets: excludeSecrets,
+		}
+		for _, r := range exportRecipients {
+			b, err := ioutil.ReadFile(r)
+			if err != nil {
+				logger.Fatal().Err(err).Msgf("Unable to read recipient public key file %s", r)
+			}
+			options.Recipients = append(options.Recipients, b)
+		}
+		if exportEncrypt && options.Passphrase == "" && len(options.Recipients) == 0 {
+			options.Passphrase, err = promptui.PromptForPassword("Passphrase to encrypt archive with", true)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Reading passphrase failed")
+			}
+		}
+
+		archive, err := env.Export(dstDir, options)
 		if err != nil {
 			logger.Fatal().Err(err).Msgf("Unable to export environment (environment id: %s)", envId.String())
 		}
+		logger.Info().Msgf("Environment exported to %s", archive)
 
 		logger.Info().Msgf("Export operation finished correctly (environment id: %s)", envId.String())
 	},
@@ -90,7 +126,11 @@ func init() {
 	envCmd.AddCommand(envExportCmd)
 
 	//TODO decide if we need this parameter at all
-	envExportCmd.Flags().StringP("id", "i", "", "id of the environment to export, default is current environment")
+	envExportCmd.Flags().StringP("id", "i", "", "id, id prefix or name of the environment to export, default is current environment")
 	envExportCmd.Flags().StringP("destination", "d", "", "destination directory to store exported archive, default is current directory")
 	_ = envExportCmd.MarkFlagDirname("destination")
+	envExportCmd.Flags().Bool("encrypt", false, "encrypt archive, passphrase is prompted for if neither passphrase nor recipient is provided")
+	envExportCmd.Flags().String("passphrase", "", "passphrase to encrypt archive with (implies encryption)")
+	envExportCmd.Flags().StringSlice("recipient", nil, "SSH public key file to encrypt archive for, can be repeated (implies encryption)")
+	envExportCmd.Flags().Bool("excludeSecrets", false, "omit private keys and secret files declared by components from archive")
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"io/ioutil"
 	"os"
 
 	"github.com/epiphany-platform/cli/internal/logger"
@@ -11,7 +12,14 @@ import (
 	"github.com/spf13/viper"
 )
 
-var srcFile string
+var (
+	srcFile          string
+	importPassphrase string
+	importIdentities []string
+	importAsNew      bool
+	importName       string
+	importOverwrite  bool
+)
 
 // envImportCmd represents envs import command
 var envImportCmd = &cobra.Command{
@@ -19,8 +27,17 @@ var envImportCmd = &cobra.Command{
 	SuggestFor: []string{"impor", "imprt"},
 	Short:      "Imports a zip compressed environment",
 	Long: `"import" command allows importing an environment from a zip archive
-and immediately switches to the imported environment`,
-	Example: "e environments import --from ba03a2ba-8fa0-4c15-ac07-894af3dbb364.zip",
+and immediately switches to the imported environment. 
+Encrypted archive is decrypted with "--passphrase" (prompted for if not provided) 
+or with one of "--identity" SSH private keys it was encrypted for. 
+Environment which already exists is refused. Use "--asNew" to import it under 
+newly generated id (optionally with "--name", otherwise name from archive is used 
+or "<name>-imported" if it is taken) or "--overwrite" to replace existing 
+environment, which is saved as snapshot first.`,
+	Example: `e environments import --from ba03a2ba-8fa0-4c15-ac07-894af3dbb364.zip
+e environments import --from ba03a2ba-8fa0-4c15-ac07-894af3dbb364.zip.enc --identity ~/.ssh/id_ed25519
+e environments import --from ba03a2ba-8fa0-4c15-ac07-894af3dbb364.zip --asNew --name copy
+e environments import --from ba03a2ba-8fa0-4c15-ac07-894af3dbb364.zip --overwrite`,
 
 	PreRun: func(cmd *cobra.Command, args []string) {
 		logger.Debug().Msg("environments import called")
@@ -31,10 +48,18 @@ and immediately switches to the imported environment`,
 		}
 
 		srcFile = viper.GetString("from")
-
+		importPassphrase = viper.GetString("passphrase")
+		importIdentities = viper.GetStringSlice("identity")
+		importAsNew = viper.GetBool("asNew")
+		importName = viper.GetString("name")
+		importOverwrite = viper.GetBool("overwrite")
 	},
 
 	Run: func(cmd *cobra.Command, args []string) {
+		if importAsNew && importOverwrite {
+			logger.Fatal().Msg("Flags asNew and overwrite cannot be used together")
+		}
+
 		// Ask user for source file path if no file to import from is specified
 		if srcFile == "" {
 			srcFile, _ = promptui.PromptForString("File to import environment from")
@@ -45,8 +70,36 @@ and immediately switches to the imported environment`,
 			logger.Fatal().Err(err).Msg("Incorrect file path specified")
 		}
 
+		options := environment.ImportOptions{
+			Passphrase: importPassphrase,
+			AsNew:      importAsNew,
+			Name:       importName,
+			Overwrite:  importOverwrite,
+		}
+		for _, i := range importIdentities {
+			b, err := ioutil.ReadFile(i)
+			if err != nil {
+				logger.Fatal().Err(err).Msgf("Unable to read identity file %s", i)
+			}
+			options.Identities = append(options.Identities, b)
+		}
+		if options.Passphrase == "" && len(options.Identities) == 0 {
+			needsPassphrase, err := environment.NeedsPassphrase(srcFile)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("Unable to read file to import environment from")
+			}
+			if needsPassphrase {
+				options.Passphrase, err = promptui.PromptForPassword("Passphrase to decrypt archive with", false)
+				if err != nil {
+					logger.Fatal().Err(err).Msg("Reading passphrase failed")
+				}
+			}
+		}
+
 		// Import environment
-		envId, err := environment.Import(srcFile)
+		ctx, cancel := signalContext()
+		defer cancel()
+		envId, err := environment.Import(ctx, srcFile, options)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("Unable to import environment from specified file")
 		}
@@ -66,4 +119,9 @@ func init() {
 
 	envImportCmd.Flags().StringP("from", "f", "", "File to import from")
 	_ = envImportCmd.MarkFlagFilename("from")
+	envImportCmd.Flags().String("passphrase", "", "passphrase to decrypt archive with")
+	envImportCmd.Flags().StringSlice("identity", nil, "SSH private key file to decrypt archive with, can be repeated")
+	envImportCmd.Flags().Bool("asNew", false, "import environment under newly generated id")
+	envImportCmd.Flags().String("name", "", "name of imported environment (name from archive, made unique with asNew, is used if empty)")
+	envImportCmd.Flags().Bool("overwrite", false, "replace existing environment with the same id, it is saved as snapshot first")
 }
@@ -2,21 +2,70 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"strings"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/spf13/cobra"
 )
 
+//environmentInfo is output schema of "environments info"
+type environmentInfo struct {
+	Name      string                `json:"name" yaml:"name"`
+	Uuid      string                `json:"uuid" yaml:"uuid"`
+	Installed []installedModuleInfo `json:"installed" yaml:"installed"`
+}
+
+//installedModuleInfo is output schema of module installed in environment
+type installedModuleInfo struct {
+	Name     string   `json:"name" yaml:"name"`
+	Type     string   `json:"type" yaml:"type"`
+	Version  string   `json:"version" yaml:"version"`
+	Image    string   `json:"image" yaml:"image"`
+	Digest   string   `json:"digest,omitempty" yaml:"digest,omitempty"`
+	Commands []string `json:"commands" yaml:"commands"`
+	Requires []string `json:"requires" yaml:"requires"`
+}
+
 // envInfoCmd represents the info command
 var envInfoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Displays information about currently selected environment",
-	Long:  `TODO`,
+	Long:  `Displays name and id of currently selected environment and modules installed in it.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		logger.Debug().Msg("environments info called")
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Print(currentEnvironment.String())
+		info := environmentInfo{
+			Name:      currentEnvironment.Name,
+			Uuid:      currentEnvironment.Uuid.String(),
+			Installed: make([]installedModuleInfo, 0, len(currentEnvironment.Installed)),
+		}
+		for _, ic := range currentEnvironment.Installed {
+			m := installedModuleInfo{
+				Name:     ic.Name,
+				Type:     ic.Type,
+				Version:  ic.Version,
+				Image:    ic.Image,
+				Digest:   ic.Digest,
+				Commands: make([]string, 0, len(ic.Commands)),
+				Requires: append([]string{}, ic.Requires...),
+			}
+			for _, c := range ic.Commands {
+				m.Commands = append(m.Commands, c.Name)
+			}
+			info.Installed = append(info.Installed, m)
+		}
+		err := printOutput(info, func(w io.Writer) {
+			_, _ = fmt.Fprintf(w, "Environment: %s (%s)\n\n", info.Name, info.Uuid)
+			row(w, "MODULE", "VERSION", "TYPE", "IMAGE", "COMMANDS", "REQUIRES")
+			for _, m := range info.Installed {
+				row(w, m.Name, m.Version, m.Type, m.Image, orDash(strings.Join(m.Commands, ",")), orDash(strings.Join(m.Requires, ",")))
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing environment info failed")
+		}
 	},
 }
 
@@ -1,7 +1,7 @@
 package cmd
 
 import (
-	"fmt"
+	"io"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/epiphany-platform/cli/pkg/environment"
@@ -9,11 +9,18 @@ import (
 	"github.com/spf13/cobra"
 )
 
+//environmentListItem is output schema of single environment in "environments list"
+type environmentListItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Uuid    string `json:"uuid" yaml:"uuid"`
+	Current bool   `json:"current" yaml:"current"`
+}
+
 // envListCmd represents the list command
 var envListCmd = &cobra.Command{
 	Use:   "list",
-	Short: "TODO",
-	Long:  `TODO`,
+	Short: "Lists environments",
+	Long:  `Lists all environments. Currently selected environment is marked with "*".`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		logger.Debug().Msg("environments list pre run called")
 	},
@@ -23,12 +30,26 @@ var envListCmd = &cobra.Command{
 		if err != nil {
 			logger.Fatal().Err(err).Msg("environments get all failed")
 		}
+		items := make([]environmentListItem, 0, len(environments))
 		for _, e := range environments {
-			if e.Uuid.String() == config.CurrentEnvironment.String() {
-				fmt.Printf("* (%s) | %s\n", e.Uuid.String(), e.Name)
-			} else {
-				fmt.Printf("  (%s) | %s\n", e.Uuid.String(), e.Name)
+			items = append(items, environmentListItem{
+				Name:    e.Name,
+				Uuid:    e.Uuid.String(),
+				Current: e.Uuid == config.CurrentEnvironment,
+			})
+		}
+		err = printOutput(items, func(w io.Writer) {
+			row(w, "CURRENT", "NAME", "UUID")
+			for _, i := range items {
+				current := ""
+				if i.Current {
+					current = "*"
+				}
+				row(w, current, i.Name, i.Uuid)
 			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing environments failed")
 		}
 	},
 }
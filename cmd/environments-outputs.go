@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"sort"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// envOutputsCmd represents the outputs command
+var envOutputsCmd = &cobra.Command{
+	Use:   "outputs",
+	Short: "Displays outputs collected from installed components",
+	Long: `"outputs" command displays outputs collected from components installed in currently 
+selected environment. If component name is provided only outputs of that component are displayed.
+Outputs are available to other components in templates as {{ output "component" "name" }}. 
+Output is a map of outputs by component name.`,
+	Example: `e environments outputs
+e environments outputs azbi`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return errors.New("there should be at most one positional argument with component name")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments outputs called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			if _, err := currentEnvironment.GetOutputs(args[0]); err != nil {
+				logger.Fatal().Err(err).Msg("getting component outputs failed")
+			}
+		}
+		outputs := make(map[string]map[string]interface{})
+		for name, values := range currentEnvironment.Outputs {
+			if len(args) == 1 && name != args[0] {
+				continue
+			}
+			outputs[name] = jsonCompatible(map[string]interface{}(values)).(map[string]interface{})
+		}
+		err := printOutput(outputs, func(w io.Writer) {
+			row(w, "MODULE", "OUTPUT", "VALUE")
+			var names []string
+			for name := range outputs {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				var keys []string
+				for k := range outputs[name] {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, k := range keys {
+					row(w, name, k, cell(outputs[name][k]))
+				}
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing outputs failed")
+		}
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envOutputsCmd)
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/spf13/cobra"
+)
+
+// envRenameCmd represents the rename command
+var envRenameCmd = &cobra.Command{
+	Use:     "rename",
+	Short:   "Renames environment",
+	Long:    `"rename" command changes name of environment referenced by its id or current name.`,
+	Example: "e environments rename staging production",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.New("'rename' command gets 2 arguments: id or name of environment and its new name")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments rename called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		e, err := environment.Resolve(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting environment failed")
+		}
+		old := e.Name
+		err = e.Rename(args[1])
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("renaming environment %s failed", e.Uuid.String())
+		}
+		logger.Info().Msgf("Environment %s was renamed from %s to %s", e.Uuid.String(), old, e.Name)
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envRenameCmd)
+}
@@ -1,24 +1,49 @@
 package cmd
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
 	"github.com/epiphany-platform/cli/pkg/processor"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	runInteractive bool
+	runTty         bool
+	runTimeout     time.Duration
+	runEnvs        []string
+)
+
 // envRunCmd represents the run command
 var envRunCmd = &cobra.Command{ //TODO consider what are options to create integration tests here. For me it seams that it would be testing of docker
 	Use:   "run",
 	Short: "Runs installed component command in environment",
-	Long:  `TODO`,
+	Long: `"run" command runs installed component command in currently selected environment. 
+If command fails inside of container, e exits with the same exit code as container did. 
+Use "-it" flags to run command which requires user input in terminal. 
+Interrupting e (or exceeding "--timeout") stops and removes container of the command. 
+Arguments provided after "--" are appended to command arguments declared by component 
+and "--env" flags override command environment variables. After successful run outputs 
+declared by component are collected from "outputs.yaml" file written to any of its mounts.`,
+	Example: `e environments run azbi apply
+e environments run -it azbi apply
+e environments run --timeout 30m azbi apply
+e environments run --env TF_LOG=DEBUG c1 apply -- -target=module.x`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 2 {
+		dash := cmd.ArgsLenAtDash()
+		if (dash < 0 && len(args) != 2) || (dash >= 0 && dash != 2) {
 			return errors.New("incorrect number of arguments")
 		}
-		return nil
+		_, err := parseEnvOverrides(runEnvs)
+		return err
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		logger.Debug().Msg("environments run called")
@@ -28,14 +53,63 @@ var envRunCmd = &cobra.Command{ //TODO consider what are options to create integ
 		if err != nil {
 			logger.Fatal().Err(err).Msg("getting component by name failed")
 		}
-		err = c.Run(args[1], processor.TemplateProcessor(config, currentEnvironment))
+		ctx, cancel := signalContext()
+		defer cancel()
+		if runTimeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, runTimeout)
+			defer cancel()
+		}
+		if currentEnvironment.AutoSnapshot {
+			s, err := currentEnvironment.CreateSnapshot(fmt.Sprintf("before run %s %s", args[0], args[1]), true)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("creating automatic snapshot failed")
+			}
+			logger.Info().Msgf("created automatic snapshot %d", s.Version)
+		}
+		envs, _ := parseEnvOverrides(runEnvs)
+		err = c.Run(ctx, args[1], processor.TemplateProcessor(config, currentEnvironment, c), environment.RunOptions{
+			Interactive: runInteractive,
+			Tty:         runTty,
+			ExtraArgs:   args[2:],
+			Envs:        envs,
+		})
+		var exitErr *environment.ExitError
+		if errors.As(err, &exitErr) {
+			logger.Error().Err(err).Msgf("running %s %s failed", args[0], args[1])
+			os.Exit(int(exitErr.Code))
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			logger.Fatal().Err(err).Msgf("running %s %s timed out after %s", args[0], args[1], runTimeout)
+		}
 		if err != nil {
 			logger.Fatal().Err(err).Msg("run command failed")
 		}
+		err = currentEnvironment.CollectOutputs(c)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("collecting component outputs failed")
+		}
 		logger.Info().Msgf("running %s %s finished", args[0], args[1])
 	},
 }
 
 func init() {
 	envCmd.AddCommand(envRunCmd)
+
+	envRunCmd.Flags().BoolVarP(&runInteractive, "interactive", "i", false, "keep stdin open and forward it to command")
+	envRunCmd.Flags().BoolVarP(&runTty, "tty", "t", false, "allocate pseudo-TTY for command")
+	envRunCmd.Flags().DurationVar(&runTimeout, "timeout", 0, "stop command if it runs longer than provided duration, e.g. 30m (0 means no timeout)")
+	envRunCmd.Flags().StringArrayVar(&runEnvs, "env", nil, "set command environment variable in KEY=VAL format, can be repeated")
+}
+
+//parseEnvOverrides converts list of KEY=VAL strings into map
+func parseEnvOverrides(list []string) (map[string]string, error) {
+	envs := make(map[string]string)
+	for _, e := range list {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("incorrect environment variable format: %s (expected KEY=VAL)", e)
+		}
+		envs[kv[0]] = kv[1]
+	}
+	return envs, nil
 }
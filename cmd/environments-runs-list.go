@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+//runListItem is output schema of single run in "environments runs list"
+type runListItem struct {
+	Id       string    `json:"id" yaml:"id"`
+	Command  string    `json:"command" yaml:"command"`
+	Version  string    `json:"version" yaml:"version"`
+	Started  time.Time `json:"started" yaml:"started"`
+	Duration string    `json:"duration" yaml:"duration"`
+	ExitCode int64     `json:"exitCode" yaml:"exitCode"`
+	Error    string    `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// envRunsListCmd represents the runs list command
+var envRunsListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists recorded runs of installed component",
+	Long:    `Lists recorded runs of installed component in currently selected environment starting from the oldest one.`,
+	Example: "e environments runs list azbi",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("there should be one positional argument with component name")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments runs list called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := currentEnvironment.GetComponentByName(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting component by name failed")
+		}
+		runs, err := c.GetRuns()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting component runs failed")
+		}
+		items := make([]runListItem, 0, len(runs))
+		for _, r := range runs {
+			items = append(items, runListItem{
+				Id:       r.Id,
+				Command:  r.Name,
+				Version:  r.Version,
+				Started:  r.Started,
+				Duration: r.Finished.Sub(r.Started).Round(time.Millisecond).String(),
+				ExitCode: r.ExitCode,
+				Error:    r.Error,
+			})
+		}
+		err = printOutput(items, func(w io.Writer) {
+			row(w, "ID", "COMMAND", "VERSION", "EXIT CODE", "DURATION")
+			for _, i := range items {
+				row(w, i.Id, i.Command, i.Version, fmt.Sprint(i.ExitCode), i.Duration)
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing runs failed")
+		}
+	},
+}
+
+func init() {
+	envRunsCmd.AddCommand(envRunsListCmd)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+//runShowInfo is output schema of "environments runs show"
+type runShowInfo struct {
+	Id        string    `json:"id" yaml:"id"`
+	Component string    `json:"component" yaml:"component"`
+	Version   string    `json:"version" yaml:"version"`
+	Name      string    `json:"name" yaml:"name"`
+	Image     string    `json:"image" yaml:"image"`
+	Digest    string    `json:"digest,omitempty" yaml:"digest,omitempty"`
+	Command   string    `json:"command" yaml:"command"`
+	Args      []string  `json:"args" yaml:"args"`
+	Started   time.Time `json:"started" yaml:"started"`
+	Finished  time.Time `json:"finished" yaml:"finished"`
+	ExitCode  int64     `json:"exitCode" yaml:"exitCode"`
+	Error     string    `json:"error,omitempty" yaml:"error,omitempty"`
+	Stdout    string    `json:"stdout" yaml:"stdout"`
+	Stderr    string    `json:"stderr" yaml:"stderr"`
+}
+
+// envRunsShowCmd represents the runs show command
+var envRunsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Shows details and output of recorded run of installed component",
+	Long: `Shows details, standard output and standard error of recorded run of installed 
+component in currently selected environment. If run id is not provided the latest 
+run is shown.`,
+	Example: `e environments runs show azbi
+e environments runs show azbi 20210420-101512.123UTC`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return errors.New("there should be component name and optional run id positional arguments")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments runs show called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := currentEnvironment.GetComponentByName(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting component by name failed")
+		}
+		id := ""
+		if len(args) == 2 {
+			id = args[1]
+		}
+		r, err := c.GetRun(id)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting component run failed")
+		}
+		stdout, stderr, err := r.Output()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("reading run output failed")
+		}
+		info := runShowInfo{
+			Id:        r.Id,
+			Component: r.Component,
+			Version:   r.Version,
+			Name:      r.Name,
+			Image:     r.Image,
+			Digest:    r.Digest,
+			Command:   r.Command,
+			Args:      r.Args,
+			Started:   r.Started,
+			Finished:  r.Finished,
+			ExitCode:  r.ExitCode,
+			Error:     r.Error,
+			Stdout:    stdout,
+			Stderr:    stderr,
+		}
+		err = printOutput(info, func(w io.Writer) {
+			row(w, "Run:", info.Id)
+			row(w, "Component:", info.Component+":"+info.Version)
+			row(w, "Name:", info.Name)
+			row(w, "Image:", info.Image)
+			row(w, "Digest:", orDash(info.Digest))
+			row(w, "Command:", info.Command)
+			row(w, "Args:", orDash(strings.Join(info.Args, " ")))
+			row(w, "Started:", info.Started.Format(time.RFC3339))
+			row(w, "Finished:", info.Finished.Format(time.RFC3339))
+			row(w, "Exit code:", fmt.Sprint(info.ExitCode))
+			row(w, "Error:", orDash(info.Error))
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing run failed")
+		}
+		if output == outputTable {
+			// output of run is printed as it is, it must not be aligned as table
+			fmt.Printf("\nStdout:\n%s", stdout)
+			fmt.Printf("\nStderr:\n%s", stderr)
+		}
+	},
+}
+
+func init() {
+	envRunsCmd.AddCommand(envRunsShowCmd)
+}
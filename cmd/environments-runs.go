@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// envRunsCmd represents the runs command
+var envRunsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Commands used to browse history of component runs in environment.",
+	Long:  `Commands used to browse history of component runs in currently selected environment.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments runs called")
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envRunsCmd)
+}
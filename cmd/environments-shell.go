@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/spf13/cobra"
+)
+
+var shell string
+
+// envShellCmd represents the shell command
+var envShellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "Starts interactive shell in installed component runtime",
+	Long: `"shell" command starts interactive shell in image of installed component 
+(or on host for components of "local" type) with all component mounts and shared 
+directory attached. It allows to inspect or fix files used by component.`,
+	Example: `e environments shell azbi
+e environments shell azbi --shell /bin/bash`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("there should be one positional argument with component name")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments shell called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		c, err := currentEnvironment.GetComponentByName(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting component by name failed")
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+		err = c.Shell(ctx, shell)
+		var exitErr *environment.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(int(exitErr.Code))
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("shell failed")
+		}
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envShellCmd)
+
+	envShellCmd.Flags().StringVar(&shell, "shell", "/bin/sh", "shell executable to start in component image")
+}
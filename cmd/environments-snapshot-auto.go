@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/spf13/cobra"
+)
+
+// envSnapshotAutoCmd represents the snapshot auto command
+var envSnapshotAutoCmd = &cobra.Command{
+	Use:   "auto",
+	Short: "Enables or disables automatic snapshots before runs",
+	Long: fmt.Sprintf(`Enables ("on") or disables ("off") automatic snapshot of currently selected environment 
+taken before every "e environments run" and "e environments apply". Only %d newest automatic snapshots are kept. 
+Without argument current setting is printed.`, environment.AutoSnapshotsKept),
+	Example: `e environments snapshot auto on
+e environments snapshot auto`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 || (len(args) == 1 && args[0] != "on" && args[0] != "off") {
+			return errors.New("there should be one optional positional argument: on or off")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments snapshot auto called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			if currentEnvironment.AutoSnapshot {
+				fmt.Println("on")
+			} else {
+				fmt.Println("off")
+			}
+			return
+		}
+		currentEnvironment.AutoSnapshot = args[0] == "on"
+		err := currentEnvironment.Save()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("saving environment failed")
+		}
+		logger.Info().Msgf("automatic snapshots of environment %s turned %s", currentEnvironment.Name, args[0])
+	},
+}
+
+func init() {
+	envSnapshotCmd.AddCommand(envSnapshotAutoCmd)
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var snapshotDescription string
+
+// envSnapshotCreateCmd represents the snapshot create command
+var envSnapshotCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates snapshot of environment",
+	Long:  `Creates snapshot of currently selected environment and prints its version.`,
+	Example: `e environments snapshot create
+e environments snapshot create --description "before upgrade of azbi"`,
+	Args: cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments snapshot create called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		s, err := currentEnvironment.CreateSnapshot(snapshotDescription, false)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("creating snapshot failed")
+		}
+		logger.Info().Msgf("created snapshot %d of environment %s", s.Version, currentEnvironment.Name)
+	},
+}
+
+func init() {
+	envSnapshotCmd.AddCommand(envSnapshotCreateCmd)
+
+	envSnapshotCreateCmd.Flags().StringVar(&snapshotDescription, "description", "", "description of snapshot")
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// envSnapshotDeleteCmd represents the snapshot delete command
+var envSnapshotDeleteCmd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Deletes snapshot of environment",
+	Long:    `Deletes snapshot of currently selected environment from snapshots store.`,
+	Example: "e environments snapshot delete 3",
+	Args: func(cmd *cobra.Command, args []string) error {
+		_, err := parseSnapshotVersion(args)
+		return err
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments snapshot delete called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		version, _ := parseSnapshotVersion(args)
+		err := currentEnvironment.DeleteSnapshot(version)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("deleting snapshot %d failed", version)
+		}
+		logger.Info().Msgf("deleted snapshot %d", version)
+	},
+}
+
+func init() {
+	envSnapshotCmd.AddCommand(envSnapshotDeleteCmd)
+}
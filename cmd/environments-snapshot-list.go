@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+//snapshotListItem is output schema of single snapshot in "environments snapshot list"
+type snapshotListItem struct {
+	Version     int       `json:"version" yaml:"version"`
+	Name        string    `json:"name" yaml:"name"`
+	Created     time.Time `json:"created" yaml:"created"`
+	Description string    `json:"description,omitempty" yaml:"description,omitempty"`
+	Auto        bool      `json:"auto" yaml:"auto"`
+	Size        int64     `json:"size" yaml:"size"`
+	Hash        string    `json:"hash" yaml:"hash"`
+}
+
+// envSnapshotListCmd represents the snapshot list command
+var envSnapshotListCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "Lists snapshots of environment",
+	Long:    `Lists snapshots of currently selected environment starting from the oldest one.`,
+	Example: "e environments snapshot list",
+	Args:    cobra.NoArgs,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments snapshot list called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		snapshots, err := currentEnvironment.Snapshots()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("getting snapshots failed")
+		}
+		items := make([]snapshotListItem, 0, len(snapshots))
+		for _, s := range snapshots {
+			items = append(items, snapshotListItem{
+				Version:     s.Version,
+				Name:        s.Name,
+				Created:     s.Created,
+				Description: s.Description,
+				Auto:        s.Auto,
+				Size:        s.Size,
+				Hash:        s.Hash,
+			})
+		}
+		err = printOutput(items, func(w io.Writer) {
+			row(w, "VERSION", "CREATED", "DESCRIPTION", "SIZE", "AUTO")
+			for _, i := range items {
+				row(w, fmt.Sprint(i.Version), i.Created.Local().Format(time.RFC3339), i.Description, fmt.Sprint(i.Size), fmt.Sprint(i.Auto))
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing snapshots failed")
+		}
+	},
+}
+
+func init() {
+	envSnapshotCmd.AddCommand(envSnapshotListCmd)
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// envSnapshotRestoreCmd represents the snapshot restore command
+var envSnapshotRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restores environment from snapshot",
+	Long: `Replaces whole directory of currently selected environment with content of snapshot. 
+Current state of environment is saved as new snapshot before restore, so restore can be reverted. 
+Environment is left untouched if snapshot cannot be extracted.`,
+	Example: "e environments snapshot restore 3",
+	Args: func(cmd *cobra.Command, args []string) error {
+		_, err := parseSnapshotVersion(args)
+		return err
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments snapshot restore called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		version, _ := parseSnapshotVersion(args)
+		e, err := currentEnvironment.RestoreSnapshot(version)
+		if err != nil {
+			logger.Fatal().Err(err).Msgf("restoring snapshot %d failed", version)
+		}
+		logger.Info().Msgf("environment %s restored from snapshot %d", e.Name, version)
+	},
+}
+
+func init() {
+	envSnapshotCmd.AddCommand(envSnapshotRestoreCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// envSnapshotCmd represents the snapshot command
+var envSnapshotCmd = &cobra.Command{
+	Use:   "snapshot",
+	Short: "Commands used to save and restore state of environment.",
+	Long: `Commands used to save and restore state of currently selected environment. 
+Snapshot contains whole environment directory (config, mounts, shared directory, keys and runs) 
+and is stored in local snapshots store under increasing version number.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments snapshot called")
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envSnapshotCmd)
+}
+
+//parseSnapshotVersion reads snapshot version from positional arguments
+func parseSnapshotVersion(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, errors.New("there should be one positional argument with snapshot version")
+	}
+	version, err := strconv.Atoi(args[0])
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("incorrect snapshot version: %s", args[0])
+	}
+	return version, nil
+}
@@ -18,25 +18,26 @@ var uu uuid.UUID
 var envUseCmd = &cobra.Command{
 	Use:   "use",
 	Short: "Allows to select environment to be used",
-	Long:  `TODO`,
+	Long: `"use" command selects environment referenced by its id, unique id prefix or name. 
+Environment is chosen from list if no argument is provided.`,
+	Example: `e environments use
+e environments use 69a1f007-ab54-4c5d-8fe3-8568ce319c61
+e environments use 69a1
+e environments use staging`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) > 1 {
-			return errors.New("'use' command gets 1 optional argument with UUID of environment to use")
+			return errors.New("'use' command gets 1 optional argument with id, id prefix or name of environment to use")
 		}
 		return nil
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("environments use called")
 		if len(args) == 1 {
-			logger.Debug().Msg("environments use called")
-			uu = uuid.MustParse(args[0])
-
-			exists, err := environment.IsExisting(uu)
+			e, err := environment.Resolve(args[0])
 			if err != nil {
-				logger.Panic().Err(err).Msgf("expected environment %s existence check failed", uu.String())
-			}
-			if !exists {
-				logger.Panic().Msgf("expected environment %s not found", uu.String())
+				logger.Fatal().Err(err).Msg("getting environment failed")
 			}
+			uu = e.Uuid
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
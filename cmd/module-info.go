@@ -3,6 +3,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"regexp"
 	"strings"
 
@@ -24,7 +25,7 @@ var moduleInfoCmd = &cobra.Command{
 		if len(args) != 1 {
 			return errors.New("there should be one positional argument")
 		}
-		r := regexp.MustCompile("^[0-9a-zA-Z-_]+/[0-9a-zA-Z-_]+:[0-9a-zA-Z-_.]+$") // TODO ensure github user and repo formats
+		r := regexp.MustCompile("^[0-9a-zA-Z-_]+/[0-9a-zA-Z-_]+(:[0-9a-zA-Z-_.+~^*<>=!, ]+)?$") // TODO ensure github user and repo formats
 		if !r.MatchString(args[0]) {
 			return fmt.Errorf("module name argument incorrectly formatted")
 		}
@@ -36,26 +37,119 @@ var moduleInfoCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		a := strings.Split(args[0], "/")
 		repoName := a[0]
-		b := strings.Split(a[1], ":")
+		b := strings.SplitN(a[1], ":", 2)
 		moduleName := b[0]
-		moduleVersion := b[1]
-		v, err := repository.GetModule(repoName, moduleName, moduleVersion)
+		moduleVersion := ""
+		if len(b) > 1 {
+			moduleVersion = b[1]
+		}
+		v, err := repositories.GetModule(repoName, moduleName, moduleVersion)
 		if err != nil {
-			logger.Error().Err(err).Msg("info failed")
+			logger.Fatal().Err(err).Msg("info failed")
+		}
+		if v == nil {
+			logger.Fatal().Msgf("module not found: %s", args[0])
 		}
-		if v != nil {
-			if zerolog.GlobalLevel() == zerolog.TraceLevel {
-				l, _ := yaml.Marshal(v)
-				logger.Trace().Msgf("will return: %s", string(l))
+		if zerolog.GlobalLevel() == zerolog.TraceLevel {
+			l, _ := yaml.Marshal(v)
+			logger.Trace().Msgf("will return: %s", string(l))
+		}
+		info := newModuleInfo(repoName, v)
+		err = printOutput(info, func(w io.Writer) {
+			row(w, "Repository:", info.Repository)
+			row(w, "Name:", info.Name)
+			row(w, "Type:", info.Type)
+			row(w, "Version:", info.Version)
+			row(w, "Latest:", fmt.Sprint(info.Latest))
+			row(w, "Image:", info.Image)
+			row(w, "Workdir:", orDash(info.WorkDirectory))
+			row(w, "Mounts:", orDash(strings.Join(info.Mounts, ",")))
+			row(w, "Shared:", orDash(info.Shared))
+			row(w, "Requires:", orDash(strings.Join(info.Requires, ",")))
+			_, _ = fmt.Fprintln(w)
+			row(w, "COMMAND", "DESCRIPTION")
+			for _, c := range info.Commands {
+				row(w, c.Name, orDash(c.Description))
+			}
+			if len(info.Outputs) > 0 {
+				_, _ = fmt.Fprintln(w)
+				row(w, "OUTPUT", "DESCRIPTION")
+				for _, o := range info.Outputs {
+					row(w, o.Name, orDash(o.Description))
+				}
 			}
-			fmt.Print(v.String())
-		} else {
-			fmt.Println("module not found")
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing module info failed")
 		}
-
 	},
 }
 
+//moduleInfo is output schema of "module info"
+type moduleInfo struct {
+	Repository    string              `json:"repository" yaml:"repository"`
+	Name          string              `json:"name" yaml:"name"`
+	Type          string              `json:"type" yaml:"type"`
+	Version       string              `json:"version" yaml:"version"`
+	Latest        bool                `json:"latest" yaml:"latest"`
+	Image         string              `json:"image" yaml:"image"`
+	WorkDirectory string              `json:"workdir" yaml:"workdir"`
+	Mounts        []string            `json:"mounts" yaml:"mounts"`
+	Shared        string              `json:"shared" yaml:"shared"`
+	Requires      []string            `json:"requires" yaml:"requires"`
+	Commands      []moduleCommandInfo `json:"commands" yaml:"commands"`
+	Outputs       []moduleOutputInfo  `json:"outputs" yaml:"outputs"`
+}
+
+//moduleCommandInfo is output schema of command provided by module
+type moduleCommandInfo struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description"`
+	Command     string            `json:"command" yaml:"command"`
+	Args        []string          `json:"args" yaml:"args"`
+	Envs        map[string]string `json:"envs" yaml:"envs"`
+}
+
+//moduleOutputInfo is output schema of output declared by module
+type moduleOutputInfo struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description" yaml:"description"`
+}
+
+func newModuleInfo(repoName string, v *repository.ComponentVersion) moduleInfo {
+	info := moduleInfo{
+		Repository:    repoName,
+		Name:          v.Name,
+		Type:          v.Type,
+		Version:       v.Version,
+		Latest:        v.IsLatest,
+		Image:         v.Image,
+		WorkDirectory: v.WorkDirectory,
+		Mounts:        append([]string{}, v.Mounts...),
+		Shared:        v.Shared,
+		Requires:      append([]string{}, v.Requires...),
+		Commands:      make([]moduleCommandInfo, 0, len(v.Commands)),
+		Outputs:       make([]moduleOutputInfo, 0, len(v.Outputs)),
+	}
+	for _, c := range v.Commands {
+		envs := make(map[string]string)
+		for k, e := range c.Envs {
+			envs[k] = e
+		}
+		info.Commands = append(info.Commands, moduleCommandInfo{
+			Name:        c.Name,
+			Description: c.Description,
+			Command:     c.Command,
+			Args:        append([]string{}, c.Args...),
+			Envs:        envs,
+		})
+	}
+	for _, o := range v.Outputs {
+		info.Outputs = append(info.Outputs, moduleOutputInfo{Name: o.Name, Description: o.Description})
+	}
+	return info
+}
+
 func init() {
 	moduleCmd.AddCommand(moduleInfoCmd)
 }
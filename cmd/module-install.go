@@ -18,12 +18,17 @@ import (
 var moduleInstallCmd = &cobra.Command{
 	Use:   "install",
 	Short: "installs module into currently used environment",
-	Long:  `TODO`,
+	Long: `"install" command installs module into currently used environment. Module 
+version can be provided as exact version, "latest" (default when omitted) or 
+semantic version range like "~0.1", "^1.2" or ">=0.1.0, <0.3.0".`,
+	Example: `e module install epiphany-platform-modules/azbi
+e module install epiphany-platform-modules/azbi:0.1.0
+e module install epiphany-platform-modules/azbi:~0.1`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			return errors.New("there should be one positional argument")
 		}
-		r := regexp.MustCompile("^[0-9a-zA-Z-_]+/[0-9a-zA-Z-_]+:[0-9a-zA-Z-_.]+$") // TODO ensure github user and repo formats
+		r := regexp.MustCompile("^[0-9a-zA-Z-_]+/[0-9a-zA-Z-_]+(:[0-9a-zA-Z-_.+~^*<>=!, ]+)?$") // TODO ensure github user and repo formats
 		if !r.MatchString(args[0]) {
 			return fmt.Errorf("module name argument incorrectly formatted")
 		}
@@ -35,37 +40,23 @@ var moduleInstallCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		a := strings.Split(args[0], "/")
 		repoName := a[0]
-		b := strings.Split(a[1], ":")
+		b := strings.SplitN(a[1], ":", 2)
 		moduleName := b[0]
-		moduleVersion := b[1]
-		v, err := repository.GetModule(repoName, moduleName, moduleVersion)
+		moduleVersion := ""
+		if len(b) > 1 {
+			moduleVersion = b[1]
+		}
+		v, err := repositories.GetModule(repoName, moduleName, moduleVersion)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("get module failed")
 		}
 		if v == nil {
 			logger.Fatal().Msgf("module not found: %s", args[0])
 		}
-		newComponent := environment.InstalledComponentVersion{
-			EnvironmentRef: currentEnvironment.Uuid,
-			Name:           v.Name,
-			Type:           v.Type,
-			Version:        v.Version,
-			Image:          v.Image,
-			WorkDirectory:  v.WorkDirectory,
-			Mounts:         v.Mounts,
-			Shared:         v.Shared,
-		}
-		for _, rc := range v.Commands {
-			nic := environment.InstalledComponentCommand{
-				Name:        rc.Name,
-				Description: rc.Description,
-				Command:     rc.Command,
-				Envs:        rc.Envs,
-				Args:        rc.Args,
-			}
-			newComponent.Commands = append(newComponent.Commands, nic)
-		}
-		err = currentEnvironment.Install(newComponent)
+		newComponent := newInstalledComponentVersion(v)
+		ctx, cancel := signalContext()
+		defer cancel()
+		err = currentEnvironment.Install(ctx, newComponent)
 		if err != nil {
 			logger.Fatal().Err(err).Msg("install module in environment failed")
 		}
@@ -76,3 +67,36 @@ var moduleInstallCmd = &cobra.Command{
 func init() {
 	moduleCmd.AddCommand(moduleInstallCmd)
 }
+
+//newInstalledComponentVersion converts repository ComponentVersion to InstalledComponentVersion of current environment
+func newInstalledComponentVersion(v *repository.ComponentVersion) environment.InstalledComponentVersion {
+	newComponent := environment.InstalledComponentVersion{
+		EnvironmentRef: currentEnvironment.Uuid,
+		Name:           v.Name,
+		Type:           v.Type,
+		Version:        v.Version,
+		Image:          v.Image,
+		WorkDirectory:  v.WorkDirectory,
+		Mounts:         v.Mounts,
+		Shared:         v.Shared,
+		Requires:       v.Requires,
+		Secrets:        v.Secrets,
+	}
+	for _, rc := range v.Commands {
+		nic := environment.InstalledComponentCommand{
+			Name:        rc.Name,
+			Description: rc.Description,
+			Command:     rc.Command,
+			Envs:        rc.Envs,
+			Args:        rc.Args,
+		}
+		newComponent.Commands = append(newComponent.Commands, nic)
+	}
+	for _, ro := range v.Outputs {
+		newComponent.Outputs = append(newComponent.Outputs, environment.InstalledComponentOutput{
+			Name:        ro.Name,
+			Description: ro.Description,
+		})
+	}
+	return newComponent
+}
@@ -2,7 +2,8 @@ package cmd
 
 import (
 	"errors"
-	"fmt"
+	"io"
+	"strings"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/epiphany-platform/cli/internal/repository"
@@ -10,14 +11,32 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var searchOptions repository.SearchOptions
+
+//searchResultItem is output schema of single module found by "module search". Versions are ordered latest first.
+type searchResultItem struct {
+	Repository  string   `json:"repository" yaml:"repository"`
+	Name        string   `json:"name" yaml:"name"`
+	Type        string   `json:"type" yaml:"type"`
+	Description string   `json:"description" yaml:"description"`
+	Tags        []string `json:"tags" yaml:"tags"`
+	Versions    []string `json:"versions" yaml:"versions"`
+}
+
 // moduleSearchCmd represents the search command
 var moduleSearchCmd = &cobra.Command{
-	Use:   "search",
-	Short: "searches for named module",
-	Long:  `TODO`,
+	Use:   "search [query]",
+	Short: "searches for modules",
+	Long: `"search" command finds modules which name, description or tags contain query. Name is also 
+matched fuzzily so "tfm" finds "terraform". Results can be filtered by module type, image registry, 
+repository or tag and are ordered by match quality. Versions of each module are listed latest first. 
+Without query all modules passing filters are listed.`,
+	Example: `e module search azure
+e module search --type docker --registry docker.io terraform
+e module search --repository epiphany-platform-modules --tag kubernetes`,
 	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return errors.New("there should be one positional argument")
+		if len(args) > 1 {
+			return errors.New("there should be at most one positional argument")
 		}
 		return nil
 	},
@@ -25,14 +44,45 @@ var moduleSearchCmd = &cobra.Command{
 		logger.Debug().Msg("module search called")
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		s, err := repository.Search(args[0])
+		if len(args) == 1 {
+			searchOptions.Query = args[0]
+		}
+		results, err := repositories.Search(searchOptions)
 		if err != nil {
-			logger.Error().Err(err).Msg("search failed")
+			logger.Fatal().Err(err).Msg("search failed")
+		}
+		items := make([]searchResultItem, 0, len(results))
+		for _, r := range results {
+			item := searchResultItem{
+				Repository:  r.Repository,
+				Name:        r.Component.Name,
+				Type:        r.Component.Type,
+				Description: r.Component.Description,
+				Tags:        append([]string{}, r.Component.Tags...),
+				Versions:    make([]string, 0, len(r.Component.Versions)),
+			}
+			for _, v := range r.Component.Versions {
+				item.Versions = append(item.Versions, v.Version)
+			}
+			items = append(items, item)
+		}
+		err = printOutput(items, func(w io.Writer) {
+			row(w, "MODULE", "VERSIONS", "TYPE", "DESCRIPTION", "TAGS")
+			for _, i := range items {
+				row(w, i.Repository+"/"+i.Name, strings.Join(i.Versions, ","), i.Type, orDash(i.Description), orDash(strings.Join(i.Tags, ",")))
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing search results failed")
 		}
-		fmt.Print(s)
 	},
 }
 
 func init() {
 	moduleCmd.AddCommand(moduleSearchCmd)
+
+	moduleSearchCmd.Flags().StringVar(&searchOptions.Type, "type", "", "show only modules of type (e.g. docker or local)")
+	moduleSearchCmd.Flags().StringVar(&searchOptions.Registry, "registry", "", "show only module versions with image from registry (e.g. docker.io)")
+	moduleSearchCmd.Flags().StringVar(&searchOptions.Repository, "repository", "", "show only modules from repository")
+	moduleSearchCmd.Flags().StringVar(&searchOptions.Tag, "tag", "", "show only modules with tag")
 }
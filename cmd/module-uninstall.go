@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// moduleUninstallCmd represents the uninstall command
+var moduleUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "uninstalls module from currently used environment",
+	Long: `"uninstall" command removes installed module from currently used environment 
+together with its mounts and runs directories. Version has to be provided only 
+if there is more than one version of module installed.`,
+	Example: `e module uninstall azbi
+e module uninstall azbi:0.1.0`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("there should be one positional argument")
+		}
+		r := regexp.MustCompile("^[0-9a-zA-Z-_]+(:[0-9a-zA-Z-_.]+)?$")
+		if !r.MatchString(args[0]) {
+			return fmt.Errorf("module name argument incorrectly formatted")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("module uninstall called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		a := strings.Split(args[0], ":")
+		moduleName := a[0]
+		moduleVersion := ""
+		if len(a) > 1 {
+			moduleVersion = a[1]
+		}
+		err := currentEnvironment.Uninstall(moduleName, moduleVersion)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("uninstall module from environment failed")
+		}
+		fmt.Printf("Uninstalled module %s from environment %s\n", args[0], currentEnvironment.Name)
+	},
+}
+
+func init() {
+	moduleCmd.AddCommand(moduleUninstallCmd)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeRepository string
+	upgradeVersion    string
+	migrateMounts     bool
+	upgradeForce      bool
+)
+
+// moduleUpgradeCmd represents the upgrade command
+var moduleUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "upgrades module installed in currently used environment",
+	Long: `"upgrade" command replaces installed version of module with the latest one 
+found in repositories or with the one selected by "--version" flag, which accepts 
+exact version, "latest" or semantic version range like "~0.2". Content of mounts 
+directory is copied to the new version unless "--migrateMounts=false" is used and runs 
+history is moved to the new version. Version which is not newer than the installed one 
+is refused unless "--force" is used.`,
+	Example: `e module upgrade azbi
+e module upgrade azbi --repository epiphany-platform-modules --version ~0.2
+e module upgrade azbi --version 0.1.0 --force`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("there should be one positional argument")
+		}
+		r := regexp.MustCompile("^[0-9a-zA-Z-_]+$")
+		if !r.MatchString(args[0]) {
+			return fmt.Errorf("module name argument incorrectly formatted")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("module upgrade called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		v, err := repositories.GetModule(upgradeRepository, args[0], upgradeVersion)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("get module failed")
+		}
+		if v == nil {
+			logger.Fatal().Msgf("module not found: %s", args[0])
+		}
+		newComponent := newInstalledComponentVersion(v)
+		ctx, cancel := signalContext()
+		defer cancel()
+		err = currentEnvironment.Upgrade(ctx, newComponent, migrateMounts, upgradeForce)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("upgrade module in environment failed")
+		}
+		fmt.Printf("Upgraded module %s to version %s in environment %s\n", newComponent.Name, newComponent.Version, currentEnvironment.Name)
+	},
+}
+
+func init() {
+	moduleCmd.AddCommand(moduleUpgradeCmd)
+
+	moduleUpgradeCmd.Flags().StringVar(&upgradeRepository, "repository", "", "name of repository to take new version of module from")
+	moduleUpgradeCmd.Flags().StringVar(&upgradeVersion, "version", "", "version or version range of module to upgrade to, default is the latest one")
+	moduleUpgradeCmd.Flags().BoolVar(&migrateMounts, "migrateMounts", true, "copy mounts directory content from old to new version")
+	moduleUpgradeCmd.Flags().BoolVar(&upgradeForce, "force", false, "allow upgrade to version which is not newer than installed one")
+}
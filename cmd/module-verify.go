@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/spf13/cobra"
+)
+
+//imageVerificationItem is output schema of single module version in "module verify"
+type imageVerificationItem struct {
+	Name     string   `json:"name" yaml:"name"`
+	Version  string   `json:"version" yaml:"version"`
+	Image    string   `json:"image" yaml:"image"`
+	Status   string   `json:"status" yaml:"status"`
+	Pinned   string   `json:"pinned" yaml:"pinned"`
+	Local    string   `json:"local" yaml:"local"`
+	Registry string   `json:"registry" yaml:"registry"`
+	Errors   []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// moduleVerifyCmd represents the verify command
+var moduleVerifyCmd = &cobra.Command{
+	Use:   "verify [name]",
+	Short: "reports drift of module images from digests pinned at install",
+	Long: `"verify" command compares content digest each module image was pinned to when module 
+was installed into currently used environment with digest its image tag points to now in 
+docker daemon and in registry. If module name is provided only that module is verified. 
+Command exits with code 1 if any image drifted.`,
+	Example: `e module verify
+e module verify azbi`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) > 1 {
+			return errors.New("there should be at most one positional argument with module name")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("module verify called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		name := ""
+		if len(args) == 1 {
+			name = args[0]
+		}
+		ctx, cancel := signalContext()
+		defer cancel()
+		verifications, err := currentEnvironment.Verify(ctx, name)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("verify failed")
+		}
+		drift := false
+		items := make([]imageVerificationItem, 0, len(verifications))
+		for _, v := range verifications {
+			items = append(items, imageVerificationItem{
+				Name:     v.Component,
+				Version:  v.Version,
+				Image:    v.Image,
+				Status:   v.Status(),
+				Pinned:   v.Pinned,
+				Local:    v.Local,
+				Registry: v.Registry,
+				Errors:   v.Errors,
+			})
+			drift = drift || v.Status() == environment.VerificationDrift
+			for _, e := range v.Errors {
+				logger.Debug().Msgf("digest of module %s image not resolved: %s", v.Component, e)
+			}
+		}
+		err = printOutput(items, func(w io.Writer) {
+			row(w, "MODULE", "VERSION", "STATUS", "PINNED", "LOCAL", "REGISTRY")
+			for _, i := range items {
+				row(w, i.Name, i.Version, i.Status, shortDigest(i.Pinned), shortDigest(i.Local), shortDigest(i.Registry))
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing verification failed")
+		}
+		if drift {
+			os.Exit(1)
+		}
+	},
+}
+
+//shortDigest shortens digest for table cell to algorithm and first 12 characters of hash as docker does
+func shortDigest(digest string) string {
+	if i := strings.Index(digest, ":"); i >= 0 && len(digest) > i+13 {
+		return digest[:i+13]
+	}
+	return orDash(digest)
+}
+
+func init() {
+	moduleCmd.AddCommand(moduleVerifyCmd)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	outputTable = "table"
+	outputJson  = "json"
+	outputYaml  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputJson, outputYaml}
+
+//validateOutputFormat checks value of global "--output" flag
+func validateOutputFormat(format string) error {
+	for _, f := range outputFormats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q (supported: %s)", format, strings.Join(outputFormats, ", "))
+}
+
+//printOutput prints v as JSON or YAML document if selected by "--output" flag. Otherwise table function is called
+//with writer aligning tab separated columns.
+func printOutput(v interface{}, table func(w io.Writer)) error {
+	switch output {
+	case outputJson:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(os.Stdout, string(b))
+		return err
+	case outputYaml:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(b)
+		return err
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+}
+
+//row writes tab separated table row
+func row(w io.Writer, columns ...string) {
+	_, _ = fmt.Fprintln(w, strings.Join(columns, "\t"))
+}
+
+//orDash returns "-" for empty table cells
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+//jsonCompatible converts maps with interface{} keys produced by YAML decoding to maps with string keys so that
+//value can be encoded as JSON
+func jsonCompatible(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = jsonCompatible(e)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[k] = jsonCompatible(e)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(t))
+		for i, e := range t {
+			l[i] = jsonCompatible(e)
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+//cell formats value as table cell, values other than scalars are formatted as compact JSON
+func cell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return "-"
+	case string:
+		return orDash(t)
+	case map[string]interface{}, []interface{}:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprint(t)
+		}
+		return string(b)
+	default:
+		return fmt.Sprint(t)
+	}
+}
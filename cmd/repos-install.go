@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"errors"
-	"regexp"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/epiphany-platform/cli/internal/repository"
@@ -12,23 +11,31 @@ import (
 )
 
 var (
-	force  bool
-	branch string
+	force         bool
+	branch        string
+	allowUnsigned bool
 )
 
 // reposInstallCmd represents the install command
 var reposInstallCmd = &cobra.Command{
-	Use:   "install",
+	Use:   "install [source]",
 	Short: "installs new repository",
+	Long: `Installs new repository from one of sources:
+  user-name/repo-name              GitHub repository (--branch selects branch)
+  file:///path/to/v1.yaml          local repository file or directory containing v1.yaml
+  https://host/path/to/v1.yaml     repository file available under URL
+  git@host:path/repo.git           Git remote with v1.yaml in its root (--branch selects branch),
+                                   also ssh://, git://, https://host/path/repo.git and git+https:// forms
+
+Repository file has to be signed with one of keys trusted with 'e repos trust add'. Signature is 
+expected next to repository file as v1.yaml.sig. It is either raw ed25519 signature (binary or base64 
+encoded) or SSH signature made with 'ssh-keygen -Y sign -n epiphany-repository'.`,
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 1 {
 			return errors.New("'install' command needs exactly one positional argument")
 		}
-		match, _ := regexp.MatchString(".*/.*", args[0])
-		if !match {
-			return errors.New("argument needs to have 'user-name/repo-name' format")
-		}
-		return nil
+		_, err := repository.ParseSource(args[0], "")
+		return err
 	},
 	PreRun: func(cmd *cobra.Command, args []string) {
 		logger.Debug().Msg("install called")
@@ -39,7 +46,7 @@ var reposInstallCmd = &cobra.Command{
 		}
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		err := repository.Install(args[0], force, branch)
+		err := repositories.Install(args[0], force, branch, allowUnsigned)
 		if err != nil {
 			logger.Error().Err(err).Msg("install failed")
 		}
@@ -51,4 +58,5 @@ func init() {
 
 	reposInstallCmd.Flags().BoolVar(&force, "force", false, "force repo install even if file already exists.")
 	reposInstallCmd.Flags().StringVar(&branch, "branch", "", "provide branch other than default HEAD")
+	reposInstallCmd.Flags().BoolVar(&allowUnsigned, "allowUnsigned", false, "install repository even if it is not signed with trusted key")
 }
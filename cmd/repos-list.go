@@ -1,14 +1,26 @@
 package cmd
 
 import (
-	"fmt"
+	"io"
 
 	"github.com/epiphany-platform/cli/internal/logger"
-	"github.com/epiphany-platform/cli/internal/repository"
 
 	"github.com/spf13/cobra"
 )
 
+//repositoryListItem is output schema of single repository in "repos list"
+type repositoryListItem struct {
+	Name    string           `json:"name" yaml:"name"`
+	Modules []moduleListItem `json:"modules" yaml:"modules"`
+}
+
+//moduleListItem is output schema of single module version available in repository
+type moduleListItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Version string `json:"version" yaml:"version"`
+	Latest  bool   `json:"latest" yaml:"latest"`
+}
+
 // repoListCmd represents the list command
 var repoListCmd = &cobra.Command{
 	Use:   "list",
@@ -17,11 +29,35 @@ var repoListCmd = &cobra.Command{
 		logger.Debug().Msg("list called")
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		s, err := repository.List()
+		repos, err := repositories.List()
 		if err != nil {
 			logger.Fatal().Err(err).Msg("list failed")
 		}
-		fmt.Print(s)
+		items := make([]repositoryListItem, 0, len(repos))
+		for _, r := range repos {
+			item := repositoryListItem{Name: r.Name, Modules: []moduleListItem{}}
+			for _, c := range r.Components {
+				for _, v := range c.Versions {
+					item.Modules = append(item.Modules, moduleListItem{Name: c.Name, Version: v.Version, Latest: v.IsLatest})
+				}
+			}
+			items = append(items, item)
+		}
+		err = printOutput(items, func(w io.Writer) {
+			row(w, "REPOSITORY", "MODULE", "VERSION", "LATEST")
+			for _, i := range items {
+				for _, m := range i.Modules {
+					latest := ""
+					if m.Latest {
+						latest = "*"
+					}
+					row(w, i.Name, m.Name, m.Version, latest)
+				}
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing repositories failed")
+		}
 	},
 }
 
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// reposRemoveCmd represents the remove command
+var reposRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "removes installed repository",
+	Long: `"remove" command deletes installed repository together with its metadata. 
+Modules already installed in environments are not affected.`,
+	Example: `e repos remove mkyc-my-epiphany-repo`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("'remove' command needs exactly one positional argument")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("repos remove called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := repositories.Remove(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("remove failed")
+		}
+		fmt.Printf("Removed repository %s\n", args[0])
+	},
+}
+
+func init() {
+	reposCmd.AddCommand(reposRemoveCmd)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// reposTrustAddCmd represents the add command
+var reposTrustAddCmd = &cobra.Command{
+	Use:   "add [name] [public-key-file]",
+	Short: "trusts key of repository publisher",
+	Long: `"add" command stores ed25519 public key of repository publisher under given name. Key file 
+can be PEM encoded public key (as produced by 'openssl pkey -pubout'), OpenSSH public key 
+(as produced by 'ssh-keygen -t ed25519') or base64 encoded raw key.`,
+	Example: `e repos trust add my-team ./my-team.pub`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return errors.New("'add' command needs exactly two positional arguments")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("repos trust add called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		b, err := ioutil.ReadFile(args[1])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("reading public key failed")
+		}
+		key, err := trustStore.Add(args[0], b)
+		if err != nil {
+			logger.Fatal().Err(err).Msg("adding trusted key failed")
+		}
+		fmt.Printf("Trusted key %s (%s)\n", key.Name, key.Fingerprint())
+	},
+}
+
+func init() {
+	reposTrustCmd.AddCommand(reposTrustAddCmd)
+}
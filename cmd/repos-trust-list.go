@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"io"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+//trustedKeyListItem is output schema of single key in "repos trust list"
+type trustedKeyListItem struct {
+	Name        string    `json:"name" yaml:"name"`
+	Fingerprint string    `json:"fingerprint" yaml:"fingerprint"`
+	Key         string    `json:"key" yaml:"key"`
+	AddedAt     time.Time `json:"addedAt" yaml:"addedAt"`
+}
+
+// reposTrustListCmd represents the list command
+var reposTrustListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "lists trusted keys",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("repos trust list called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		keys, err := trustStore.List()
+		if err != nil {
+			logger.Fatal().Err(err).Msg("list failed")
+		}
+		items := make([]trustedKeyListItem, 0, len(keys))
+		for _, k := range keys {
+			items = append(items, trustedKeyListItem{Name: k.Name, Fingerprint: k.Fingerprint(), Key: k.Key, AddedAt: k.AddedAt})
+		}
+		err = printOutput(items, func(w io.Writer) {
+			row(w, "NAME", "FINGERPRINT", "ADDED")
+			for _, i := range items {
+				row(w, i.Name, i.Fingerprint, i.AddedAt.Format(time.RFC3339))
+			}
+		})
+		if err != nil {
+			logger.Fatal().Err(err).Msg("printing trusted keys failed")
+		}
+	},
+}
+
+func init() {
+	reposTrustCmd.AddCommand(reposTrustListCmd)
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// reposTrustRemoveCmd represents the remove command
+var reposTrustRemoveCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "stops trusting key of repository publisher",
+	Long: `"remove" command deletes trusted key. Already installed repositories signed with it are 
+not affected but they cannot be updated until other key they are signed with is trusted.`,
+	Example: `e repos trust remove my-team`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("'remove' command needs exactly one positional argument")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("repos trust remove called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := trustStore.Remove(args[0])
+		if err != nil {
+			logger.Fatal().Err(err).Msg("remove failed")
+		}
+		fmt.Printf("Removed trusted key %s\n", args[0])
+	},
+}
+
+func init() {
+	reposTrustCmd.AddCommand(reposTrustRemoveCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/spf13/cobra"
+)
+
+// reposTrustCmd represents the trust command
+var reposTrustCmd = &cobra.Command{
+	Use:   "trust",
+	Short: "Commands related to keys of trusted repository publishers",
+	Long: `Commands related to keys of trusted repository publishers. Repositories are installed and 
+updated only if their repository file is signed with one of trusted ed25519 keys.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("repos trust called")
+	},
+}
+
+func init() {
+	reposCmd.AddCommand(reposTrustCmd)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/internal/repository"
+
+	"github.com/spf13/cobra"
+)
+
+var updateAll bool
+
+// reposUpdateCmd represents the update command
+var reposUpdateCmd = &cobra.Command{
+	Use:   "update [name]",
+	Short: "updates installed repository",
+	Long: `"update" command fetches repository again from source it was installed from and 
+shows module versions added and removed by update. As with "install" command repository has to be 
+signed with trusted key.`,
+	Example: `e repos update epiphany-platform-modules
+e repos update --all`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if updateAll && len(args) != 0 {
+			return errors.New("repository name cannot be used together with '--all'")
+		}
+		if !updateAll && len(args) != 1 {
+			return errors.New("'update' command needs exactly one positional argument or '--all' flag")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("repos update called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if !updateAll {
+			changes, err := repositories.Update(args[0], allowUnsigned)
+			if err != nil {
+				logger.Fatal().Err(err).Msg("update failed")
+			}
+			printChanges(args[0], changes)
+			return
+		}
+		all, err := repositories.UpdateAll(allowUnsigned)
+		var names []string
+		for name := range all {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			printChanges(name, all[name])
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("update failed")
+		}
+	},
+}
+
+func printChanges(name string, changes *repository.Changes) {
+	if changes.IsEmpty() {
+		fmt.Printf("Repository %s is up to date\n", name)
+		return
+	}
+	fmt.Printf("Updated repository %s\n", name)
+	for _, v := range changes.Added {
+		fmt.Printf("\t+ %s\n", v)
+	}
+	for _, v := range changes.Removed {
+		fmt.Printf("\t- %s\n", v)
+	}
+}
+
+func init() {
+	reposCmd.AddCommand(reposUpdateCmd)
+
+	reposUpdateCmd.Flags().BoolVar(&updateAll, "all", false, "update all installed repositories")
+	reposUpdateCmd.Flags().BoolVar(&allowUnsigned, "allowUnsigned", false, "update repository even if it is not signed with trusted key")
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/internal/repository"
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// reposValidateCmd represents the validate command
+var reposValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "validates repository file",
+	Long: `Checks repository file against repository schema and lists all problems found with file name 
+and line number. Unknown fields are reported as problems too, while loading of installed 
+repositories only warns about them. Exits with code 1 if file is not valid repository.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("'validate' command needs exactly one positional argument")
+		}
+		return nil
+	},
+	PreRun: func(cmd *cobra.Command, args []string) {
+		logger.Debug().Msg("validate called")
+
+		err := viper.BindPFlags(cmd.Flags())
+		if err != nil {
+			logger.Fatal().Err(err)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := repository.ValidateFile(args[0], environment.RuntimeTypes())
+		var validationErrors repository.ValidationErrors
+		if errors.As(err, &validationErrors) {
+			for _, e := range validationErrors {
+				fmt.Fprintln(os.Stderr, e.Error())
+			}
+			os.Exit(1)
+		}
+		if err != nil {
+			logger.Fatal().Err(err).Msg("validation failed")
+		}
+		fmt.Printf("%s is valid repository\n", args[0])
+	},
+}
+
+func init() {
+	reposCmd.AddCommand(reposValidateCmd)
+}
@@ -1,14 +1,20 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
+	"os/signal"
 	"path"
+	"strings"
+	"syscall"
 
 	"github.com/epiphany-platform/cli/pkg/environment"
 
 	"github.com/epiphany-platform/cli/internal/janitor"
 
 	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/internal/repository"
 	"github.com/epiphany-platform/cli/internal/util"
 	"github.com/epiphany-platform/cli/pkg/configuration"
 
@@ -22,6 +28,9 @@ var (
 	logLevel           string
 	config             *configuration.Config
 	currentEnvironment *environment.Environment
+	repositories       *repository.Repository
+	trustStore         *repository.TrustStore
+	output             string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -30,6 +39,9 @@ var rootCmd = &cobra.Command{
 	Long: `E wrapper allows to interact with epiphany`,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
 		logger.Debug().Msg("root PersistentPreRun")
+		if err := validateOutputFormat(output); err != nil {
+			logger.Fatal().Err(err).Msg("incorrect output format")
+		}
 		var usedConfigDir string
 		if cfgDir != "" {
 			logger.Trace().Msg("configDir parameter not empty")
@@ -43,6 +55,8 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			logger.Fatal().Err(err).Msg("initialization failed")
 		}
+		trustStore = repository.NewTrustStore(util.UsedTrustedKeysFile)
+		repositories = repository.New(util.UsedReposDirectory, trustStore)
 		logger.Trace().Msg("will configuration.GetConfig()")
 		config, err = configuration.GetConfig()
 		if err != nil {
@@ -64,12 +78,31 @@ func Execute() {
 	}
 }
 
+//signalContext returns context which gets cancelled when SIGINT or SIGTERM is received. After first signal
+//default signal handling is restored so next signal terminates e immediately.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		defer signal.Stop(sigCh)
+		select {
+		case s := <-sigCh:
+			logger.Warn().Msgf("received %s signal, cancelling", s)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
 func init() {
 	logger.Initialize()
 
 	cobra.OnInitialize(initConfig)
 
 	rootCmd.PersistentFlags().StringVar(&cfgDir, "configDir", "", fmt.Sprintf("config directory (default is %s)", util.DefaultConfigurationDirectory))
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", outputTable, fmt.Sprintf("output format of list and info commands (values: [%s])", strings.Join(outputFormats, ", ")))
 	rootCmd.PersistentFlags().StringVar(&logLevel, "logLevel", "", fmt.Sprintf("log level (default is warn, values: [trace, debug, info, error, fatal])"))
 }
 
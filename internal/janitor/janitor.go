@@ -32,8 +32,7 @@ func InitializeStructure(directory string) error {
 	logger.Trace().Msg("will ensureRepository()")
 	err = ensureRepository()
 	if err != nil {
-		logger.Error().Err(err).Msg("ensureRepository() failed in InitializeStructure(directory string)")
-		return err
+		logger.Warn().Err(err).Msg("default repository was not installed, it will be tried again with next command")
 	}
 	return nil
 }
@@ -78,6 +77,21 @@ func setUsedConfigPaths(configDir string) {
 	} else {
 		logger.Debug().Msgf("util.UsedReposDirectory is already %s", util.UsedTempDirectory)
 	}
+
+	logger.Debug().Msg("will try to set used trusted keys file")
+	if util.UsedTrustedKeysFile == "" {
+		util.UsedTrustedKeysFile = path.Join(configDir, util.DefaultTrustedKeysFileName)
+	} else {
+		logger.Debug().Msgf("util.UsedTrustedKeysFile is already %s", util.UsedTrustedKeysFile)
+	}
+
+	logger.Debug().Msg("will try to set used snapshots directory")
+	if util.UsedSnapshotsDirectory == "" {
+		util.UsedSnapshotsDirectory = path.Join(configDir, util.DefaultSnapshotsSubdirectory)
+		util.EnsureDirectory(util.UsedSnapshotsDirectory)
+	} else {
+		logger.Debug().Msgf("util.UsedSnapshotsDirectory is already %s", util.UsedSnapshotsDirectory)
+	}
 }
 
 //ensureConfig initializes new config if one does not exists
@@ -112,7 +126,8 @@ func ensureEnvironment() error {
 	return nil
 }
 
-// ensureRepository tries to install default repository (but not forcibly)
+// ensureRepository tries to install default repository (but not forcibly). It is called with every command, so that
+// installation which failed (e.g. because of missing network) is retried. Already installed repository is not fetched.
 func ensureRepository() error {
-	return repository.Init()
+	return repository.New(util.UsedReposDirectory, repository.NewTrustStore(util.UsedTrustedKeysFile)).Init()
 }
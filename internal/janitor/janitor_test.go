@@ -36,7 +36,7 @@ func Test_setUsedConfigPaths(t *testing.T) {
 		{
 			name:      "happy path",
 			configDir: path.Join(confDir, "hp"),
-			wantDirs:  []string{"hp", "hp/environments", "hp/tmp", "hp/repos"},
+			wantDirs:  []string{"hp", "hp/environments", "hp/tmp", "hp/repos", "hp/snapshots"},
 		},
 	}
 
@@ -48,6 +48,8 @@ func Test_setUsedConfigPaths(t *testing.T) {
 			util.UsedConfigFile = ""
 			util.UsedEnvironmentDirectory = ""
 			util.UsedTempDirectory = ""
+			util.UsedTrustedKeysFile = ""
+			util.UsedSnapshotsDirectory = ""
 
 			setUsedConfigPaths(tt.configDir)
 
@@ -69,6 +71,8 @@ func Test_ensureConfig(t *testing.T) {
 	util.UsedConfigFile = ""
 	util.UsedEnvironmentDirectory = ""
 	util.UsedTempDirectory = ""
+	util.UsedTrustedKeysFile = ""
+	util.UsedSnapshotsDirectory = ""
 	setUsedConfigPaths(confDir)
 
 	tests := []struct {
@@ -113,6 +117,8 @@ func Test_ensureEnvironment(t *testing.T) {
 	util.UsedConfigFile = ""
 	util.UsedEnvironmentDirectory = ""
 	util.UsedTempDirectory = ""
+	util.UsedTrustedKeysFile = ""
+	util.UsedSnapshotsDirectory = ""
 	setUsedConfigPaths(confDir)
 
 	tests := []struct {
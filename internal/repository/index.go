@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+)
+
+//indexKey identifies single version of component in repository (repository name is name declared in repository file)
+type indexKey struct {
+	repo      string
+	component string
+	version   string
+}
+
+//indexedFile is decoded repository file together with state of file it was decoded from
+type indexedFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+	v1      *V1 // nil if file is not valid repository
+}
+
+//index is immutable in-memory view of repositories installed in directory
+type index struct {
+	names      []string                       // installed repository names (file names without extension) in lexical order
+	files      map[string]*indexedFile        // by installed repository name
+	versions   map[indexKey]*ComponentVersion // with Name and Type of component filled
+	components map[string][]*V1               // repositories providing component by component name, in names order
+}
+
+//scan returns state of repository files in directory. Files with extensions other than ".yaml" and ".yml" are
+//ignored.
+func scan(directory string) (map[string]*indexedFile, error) {
+	infos, err := ioutil.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*indexedFile)
+	for _, info := range infos {
+		if info.IsDir() {
+			logger.Trace().Msgf("%s is directory", info.Name())
+			continue
+		}
+		ext := filepath.Ext(info.Name())
+		if ext != ".yaml" && ext != ".yml" {
+			logger.Trace().Msgf("file %s has unrecognized extension: %s", info.Name(), ext)
+			continue
+		}
+		result[strings.TrimSuffix(info.Name(), ext)] = &indexedFile{
+			path:    path.Join(directory, info.Name()),
+			modTime: info.ModTime(),
+			size:    info.Size(),
+		}
+	}
+	return result, nil
+}
+
+//upToDate checks if index was built from files in given state
+func (idx *index) upToDate(files map[string]*indexedFile) bool {
+	if idx == nil || len(idx.files) != len(files) {
+		return false
+	}
+	for name, f := range files {
+		indexed, ok := idx.files[name]
+		if !ok || indexed.path != f.path || !indexed.modTime.Equal(f.modTime) || indexed.size != f.size {
+			return false
+		}
+	}
+	return true
+}
+
+//buildIndex decodes repository files and indexes their content. Decoded repositories of files not changed since
+//previous index was built are reused.
+func buildIndex(files map[string]*indexedFile, previous *index) *index {
+	idx := &index{
+		files:      files,
+		versions:   make(map[indexKey]*ComponentVersion),
+		components: make(map[string][]*V1),
+	}
+	for name := range files {
+		idx.names = append(idx.names, name)
+	}
+	sort.Strings(idx.names)
+	for _, name := range idx.names {
+		f := files[name]
+		if previous != nil {
+			if p, ok := previous.files[name]; ok && p.path == f.path && p.modTime.Equal(f.modTime) && p.size == f.size {
+				f.v1 = p.v1
+				idx.add(f.v1)
+				continue
+			}
+		}
+		logger.Trace().Msgf("will try to decode file: %s", f.path)
+		v1, err := decodeV1Repository(f.path)
+		if err != nil {
+			logger.Error().Msgf("file %s is not valid repository and is skipped (check it with 'e repos validate %s'):\n%s", f.path, f.path, err.Error())
+			continue
+		}
+		f.v1 = v1
+		idx.add(v1)
+	}
+	return idx
+}
+
+//add indexes components of repository
+func (idx *index) add(v1 *V1) {
+	if v1 == nil {
+		return
+	}
+	for i := range v1.Components {
+		c := &v1.Components[i]
+		idx.components[c.Name] = append(idx.components[c.Name], v1)
+		for j := range c.Versions {
+			cv := c.Versions[j]
+			cv.Name = c.Name
+			cv.Type = c.Type
+			idx.versions[indexKey{repo: v1.Name, component: c.Name, version: cv.Version}] = &cv
+		}
+	}
+}
+
+//repositories returns valid installed repositories in names order
+func (idx *index) repositories() []*V1 {
+	var result []*V1
+	for _, name := range idx.names {
+		if v1 := idx.files[name].v1; v1 != nil {
+			result = append(result, v1)
+		}
+	}
+	return result
+}
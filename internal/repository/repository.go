@@ -7,12 +7,11 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"os"
 	"path"
-	"path/filepath"
 	"regexp"
-	"strings"
+	"sort"
+	"sync"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/epiphany-platform/cli/internal/util"
@@ -21,14 +20,14 @@ import (
 )
 
 var (
-	loaded     repositories
-	httpClient = &http.Client{}
-)
+	httpClient            = &http.Client{}
+	nonAlphanumericRegexp = regexp.MustCompile("[^a-zA-Z0-9]+")
 
-type repositories struct {
-	v1s []V1
-	// add next versions of repositories here
-}
+	//ErrAlreadyInstalled is returned by Install if repository is already installed
+	ErrAlreadyInstalled = errors.New("is already installed")
+
+	errNotFound = errors.New("not found")
+)
 
 func init() {
 	logger.Initialize()
@@ -47,6 +46,12 @@ func (cc *ComponentCommand) String() string {
 	return fmt.Sprintf("    Command:\n     Name %s\n     Description %s\n", cc.Name, cc.Description)
 }
 
+//ComponentOutput struct contains information about value produced by component which other components can use
+type ComponentOutput struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
 //ComponentVersion struct contains information about version of component available to be installed
 type ComponentVersion struct {
 	Name          string             `yaml:"-"`
@@ -58,6 +63,9 @@ type ComponentVersion struct {
 	Mounts        []string           `yaml:"mounts"`
 	Shared        string             `yaml:"shared"`
 	Commands      []ComponentCommand `yaml:"commands"`
+	Outputs       []ComponentOutput  `yaml:"outputs,omitempty"`
+	Requires      []string           `yaml:"requires,omitempty"` // names of components which have to be installed first
+	Secrets       []string           `yaml:"secrets,omitempty"`  // paths (or patterns) of secret files in mounts or shared
 }
 
 func (cv *ComponentVersion) String() string {
@@ -75,9 +83,11 @@ func (cv *ComponentVersion) String() string {
 
 //Component struct is main element in repository identifying component and gathering all versions of it
 type Component struct {
-	Name     string             `yaml:"name"`
-	Type     string             `yaml:"type"`
-	Versions []ComponentVersion `yaml:"versions"`
+	Name        string             `yaml:"name"`
+	Type        string             `yaml:"type"`
+	Description string             `yaml:"description,omitempty"`
+	Tags        []string           `yaml:"tags,omitempty"`
+	Versions    []ComponentVersion `yaml:"versions"`
 }
 
 func (c *Component) String() string {
@@ -97,173 +107,377 @@ type V1 struct {
 	Components []Component `yaml:"components"`
 }
 
-func Init() error {
-	return Install(util.DefaultRepository, false, util.DefaultRepositoryBranch)
+//Repository gives access to repositories installed in directory. Installed repositories are indexed in memory and
+//index is rebuilt only when repository files change (their modification time or size differs). Signatures are
+//verified with keys from TrustStore when repositories are fetched by Install and Update, files already installed
+//are trusted as they are. Repository is safe for concurrent use.
+type Repository struct {
+	directory string
+	trust     *TrustStore
+
+	mu  sync.Mutex // guards idx
+	idx *index
+
+	changeMu sync.Mutex // serializes changes of repository files
+}
+
+//New returns Repository of repositories installed in directory verified with keys from trust store
+func New(directory string, trust *TrustStore) *Repository {
+	return &Repository{directory: directory, trust: trust}
 }
 
-func List() (string, error) {
-	err := load()
+//snapshot returns index of current state of repository files
+func (r *Repository) snapshot() (*index, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	files, err := scan(r.directory)
 	if err != nil {
 		logger.Error().Err(err).Msg("unable to load repos")
-		return "", err
+		return nil, err
+	}
+	if r.idx.upToDate(files) {
+		return r.idx, nil
 	}
+	logger.Debug().Msgf("will rebuild index of repositories in %s", r.directory)
+	r.idx = buildIndex(files, r.idx)
+	return r.idx, nil
+}
 
-	var sb strings.Builder
-	for _, v1 := range loaded.v1s {
-		sb.WriteString(fmt.Sprintf("Repository: %s\n", v1.Name))
-		for _, c := range v1.Components {
-			for _, v := range c.Versions {
-				sb.WriteString(fmt.Sprintf("\tModule: %s:%s\n", c.Name, v.Version))
+//invalidate drops index after repository files were changed, as change might not be visible in file modification
+//time with coarse file system timestamps
+func (r *Repository) invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.idx = nil
+}
+
+//Init installs default repository if it is not installed yet. Default repository has to be signed with trusted key
+//as any other repository. If it is not, it is not installed and only warning with instruction how to install it
+//explicitly is logged.
+func (r *Repository) Init() error {
+	err := r.Install(util.DefaultRepository, false, util.DefaultRepositoryBranch, false)
+	if errors.Is(err, ErrAlreadyInstalled) {
+		return nil
+	}
+	if errors.Is(err, ErrUnsigned) || errors.Is(err, ErrUntrusted) {
+		logger.Warn().Msgf("default repository was not installed: %v (run 'e repos install %s --allowUnsigned' if you trust it)", errors.Unwrap(err), util.DefaultRepository)
+		return nil
+	}
+	return err
+}
+
+//List returns valid installed repositories ordered by name of repository file
+func (r *Repository) List() ([]V1, error) {
+	idx, err := r.snapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	var result []V1
+	for _, v1 := range idx.repositories() {
+		// copy components so that index cannot be modified by caller
+		r := *v1
+		r.Components = make([]Component, len(v1.Components))
+		for i, c := range v1.Components {
+			c.Versions = append([]ComponentVersion{}, c.Versions...)
+			r.Components[i] = c
+		}
+		result = append(result, r)
+	}
+	return result, nil
+}
+
+//GetModule returns version of module selected by moduleVersion expression which can be exact version, "latest"
+//(also assumed when expression is empty) or semantic version range like "~0.1". If repoName is empty all
+//repositories are searched and module has to be found in exactly one of them.
+func (r *Repository) GetModule(repoName, moduleName, moduleVersion string) (*ComponentVersion, error) {
+	idx, err := r.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	var result *ComponentVersion
+	foundIn := ""
+	for _, v1 := range idx.components[moduleName] {
+		if repoName != "" && repoName != v1.Name {
+			continue
+		}
+		v, ok := idx.versions[indexKey{repo: v1.Name, component: moduleName, version: moduleVersion}]
+		if !ok {
+			for _, c := range v1.Components {
+				if c.Name != moduleName {
+					continue
+				}
+				selected, err := selectVersion(c.Versions, moduleVersion)
+				if err != nil {
+					return nil, err
+				}
+				if selected != nil {
+					v = idx.versions[indexKey{repo: v1.Name, component: moduleName, version: selected.Version}]
+				}
 			}
 		}
+		if v == nil {
+			continue
+		}
+		if result != nil {
+			return nil, fmt.Errorf("module %s found in multiple repositories (%s and %s), repository has to be specified", moduleName, foundIn, v1.Name)
+		}
+		cv := *v
+		result = &cv
+		foundIn = v1.Name
 	}
-	return sb.String(), nil
+	return result, nil
 }
 
-func Install(repo string, force bool, branch string) error {
-	err := load()
+//Install fetches repository from source (see ParseSource) and persists it together with its Metadata. If
+//repository is already installed ErrAlreadyInstalled is returned unless force is set. Repository which is not signed
+//with trusted key is refused (ErrUnsigned or ErrUntrusted is returned) unless allowUnsigned is set.
+func (r *Repository) Install(repo string, force bool, branch string, allowUnsigned bool) error {
+	source, err := ParseSource(repo, branch)
 	if err != nil {
-		logger.Error().Err(err).Msg("unable to load repos")
 		return err
 	}
-	inferredRepoName := inferRepoName(repo)
+	r.changeMu.Lock()
+	defer r.changeMu.Unlock()
+	defer r.invalidate()
+	inferredRepoName := inferRepoName(source)
 	if !force {
-		for _, v1 := range loaded.v1s {
-			if v1.Name == inferredRepoName {
-				logger.Debug().Msgf("looks like repo with name %s is already installed", inferredRepoName)
-				return nil
-			}
+		if _, err = os.Stat(r.repoFilePath(inferredRepoName)); err == nil {
+			logger.Debug().Msgf("looks like repo with name %s is already installed", inferredRepoName)
+			return fmt.Errorf("repository %s %w (use '--force' to install it again or 'e repos update %s' to refetch it)", inferredRepoName, ErrAlreadyInstalled, inferredRepoName)
 		}
 	}
 
 	logger.Debug().Msgf("will try to install %s", repo)
-	b := util.DefaultRepositoryBranch
-	if branch != "" {
-		b = branch
-	}
-	u, err := url.Parse(repo)
+	v1, m, err := r.retrieve(source, allowUnsigned)
 	if err != nil {
 		return err
 	}
-	r, err := downloadV1Repository(fmt.Sprintf("%s/%s/%s/%s", util.GithubUrl, u.Path, b, util.DefaultV1RepositoryFileName))
+	if v1.Name == "" {
+		v1.Name = inferredRepoName
+	}
+	err = r.persistV1RepositoryFile(inferredRepoName, v1, force)
 	if err != nil {
 		return err
 	}
-	if r == nil {
-		logger.Error().Msgf("no repo")
+	return r.persistMetadata(inferredRepoName, m)
+}
+
+//Changes struct lists module versions (in "module:version" form) added and removed by repository update
+type Changes struct {
+	Added   []string
+	Removed []string
+}
+
+//IsEmpty returns true if update changed no module versions
+func (c *Changes) IsEmpty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0
+}
+
+//Update fetches named repository again from source recorded in its Metadata and replaces installed repository file
+//with fetched one. Module versions added and removed by update are returned. Signature is verified the same way as
+//by Install.
+func (r *Repository) Update(name string, allowUnsigned bool) (*Changes, error) {
+	repoName, err := r.resolveName(name)
+	if err != nil {
+		return nil, err
+	}
+	r.changeMu.Lock()
+	defer r.changeMu.Unlock()
+	defer r.invalidate()
+	m, err := r.GetMetadata(repoName)
+	if err != nil {
+		return nil, err
+	}
+	var previous *V1
+	if _, err = os.Stat(r.repoFilePath(repoName)); err == nil {
+		previous, err = decodeV1Repository(r.repoFilePath(repoName))
+		if err != nil {
+			logger.Warn().Msgf("installed repository %s is not valid and will be replaced: %v", repoName, err)
+		}
+	}
+	logger.Debug().Msgf("will try to update %s from %s", repoName, m.Source)
+	v1, updated, err := r.retrieve(m.Source, allowUnsigned)
+	if err != nil {
+		return nil, err
+	}
+	if v1.Name == "" {
+		v1.Name = repoName
+	}
+	if updated.Hash == m.Hash && previous != nil {
+		logger.Debug().Msgf("content of repository %s did not change", repoName)
+	} else {
+		err = r.persistV1RepositoryFile(repoName, v1, true)
+		if err != nil {
+			return nil, err
+		}
 	}
-	if r.Name == "" {
-		r.Name = inferredRepoName
+	err = r.persistMetadata(repoName, updated)
+	if err != nil {
+		return nil, err
 	}
-	return persistV1RepositoryFile(inferredRepoName, r, force)
+	return diff(previous, v1), nil
 }
 
-func Search(name string) (string, error) {
-	err := load()
+//UpdateAll updates all installed repositories with recorded Metadata. Changes are returned by repository name.
+//Repositories without Metadata are skipped.
+func (r *Repository) UpdateAll(allowUnsigned bool) (map[string]*Changes, error) {
+	idx, err := r.snapshot()
 	if err != nil {
-		logger.Error().Err(err).Msg("unable to load repos")
-		return "", err
+		return nil, err
 	}
-
-	var sb strings.Builder
-	for _, v1 := range loaded.v1s {
-		for _, c := range v1.Components {
-			if c.Name == name {
-				for _, v := range c.Versions {
-					sb.WriteString(fmt.Sprintf("%s/%s:%s\n", v1.Name, c.Name, v.Version))
-				}
-			}
+	result := make(map[string]*Changes)
+	for _, name := range idx.names {
+		if _, err = os.Stat(r.metadataFilePath(name)); os.IsNotExist(err) {
+			logger.Warn().Msgf("no metadata recorded for repository %s, skipping it", name)
+			continue
+		}
+		c, err := r.Update(name, allowUnsigned)
+		if err != nil {
+			return result, fmt.Errorf("update of repository %s failed: %w", name, err)
 		}
+		result[name] = c
 	}
-	return sb.String(), nil
+	return result, nil
 }
 
-func GetModule(repoName, moduleName, moduleVersion string) (*ComponentVersion, error) {
-	err := load()
+//Remove deletes named repository file and its Metadata
+func (r *Repository) Remove(name string) error {
+	repoName, err := r.resolveName(name)
 	if err != nil {
-		logger.Error().Err(err).Msg("unable to load repos")
-		return nil, err
+		return err
 	}
-	for _, v1 := range loaded.v1s {
-		if repoName != "" && repoName == v1.Name {
-			for _, c := range v1.Components {
-				if c.Name == moduleName {
-					for _, v := range c.Versions {
-						if moduleVersion != "" && moduleVersion == v.Version {
-							v.Name = c.Name
-							v.Type = c.Type
-							return &v, nil
-						}
-					}
-				}
-			}
+	r.changeMu.Lock()
+	defer r.changeMu.Unlock()
+	defer r.invalidate()
+	if repoName == inferRepoName(Source{Type: SourceGithub, Origin: util.DefaultRepository}) {
+		logger.Warn().Msgf("repository %s is default repository and it will be installed again on next run", repoName)
+	}
+	logger.Debug().Msgf("will remove files of repository %s", repoName)
+	err = os.Remove(r.repoFilePath(repoName))
+	if err != nil {
+		return err
+	}
+	err = os.Remove(r.metadataFilePath(repoName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+//resolveName returns name of installed repository file for repository name which can be also name declared
+//inside of repository file
+func (r *Repository) resolveName(name string) (string, error) {
+	idx, err := r.snapshot()
+	if err != nil {
+		return "", err
+	}
+	if _, ok := idx.files[name]; ok {
+		return name, nil
+	}
+	for _, n := range idx.names {
+		if v1 := idx.files[n].v1; v1 != nil && v1.Name == name {
+			return n, nil
 		}
 	}
-	return nil, nil
+	return "", fmt.Errorf("repository %s is not installed", name)
 }
 
-func load() error {
-	loaded = repositories{}
-	return filepath.Walk(util.UsedReposDirectory, func(path string, info os.FileInfo, err error) error {
-		if info.IsDir() {
-			logger.Trace().Msgf("%s is directory", path)
-			return nil
+//diff returns module versions present only in current repository as added and present only in previous repository
+//as removed
+func diff(previous, current *V1) *Changes {
+	versions := func(v1 *V1) map[string]bool {
+		result := make(map[string]bool)
+		if v1 == nil {
+			return result
 		}
-		if filepath.Ext(path) == ".yaml" || filepath.Ext(path) == ".yml" {
-			logger.Trace().Msgf("will try to decode file: %s", path)
-			v1, err2 := decodeV1Repository(path)
-			if err2 != nil {
-				logger.Error().Err(err2).Msgf("file %s decode operation failed", path)
-				return nil
+		for _, c := range v1.Components {
+			for _, v := range c.Versions {
+				result[fmt.Sprintf("%s:%s", c.Name, v.Version)] = true
 			}
-			logger.Trace().Msgf("will add decoded content of %s to list of repos", path)
-			loaded.v1s = append(loaded.v1s, *v1)
-		} else {
-			logger.Trace().Msgf("file %s has unrecognized extension: %s", path, filepath.Ext(path))
 		}
-		return nil
-	})
+		return result
+	}
+	p, c := versions(previous), versions(current)
+	changes := &Changes{}
+	for k := range c {
+		if !p[k] {
+			changes.Added = append(changes.Added, k)
+		}
+	}
+	for k := range p {
+		if !c[k] {
+			changes.Removed = append(changes.Removed, k)
+		}
+	}
+	sort.Strings(changes.Added)
+	sort.Strings(changes.Removed)
+	return changes
 }
 
-//The decodeV1Repository method loads V1 from provided file path
+//The decodeV1Repository method validates file under provided path and loads V1 from it
 func decodeV1Repository(filePath string) (*V1, error) {
-	repo := &V1{}
 	content, err := ioutil.ReadFile(filePath)
 	if err != nil {
 		return nil, err
 	}
 	logger.Trace().Msgf("read content of file %s : \n%s", filePath, content)
-	err = yaml.Unmarshal(content, repo)
+	return parseV1Repository(filePath, content)
+}
+
+//parseV1Repository validates content of repository file (file is used only in validation errors) and unmarshalls
+//it to V1. Unknown fields are only logged.
+func parseV1Repository(file string, content []byte) (*V1, error) {
+	warnings, err := validate(file, content, validateOptions{})
 	if err != nil {
 		return nil, err
 	}
-	if repo.Version != "v1" {
-		return nil, fmt.Errorf("repo file has unexpected Version field: %s", repo.Version)
+	for _, w := range warnings {
+		logger.Warn().Msgf("%s (field is ignored)", w.Error())
 	}
-	if repo.Kind != "Repository" {
-		return nil, fmt.Errorf("repo file has unexpected Kind field: %s", repo.Kind)
+	repo := &V1{}
+	err = yaml.Unmarshal(content, repo)
+	if err != nil {
+		return nil, err
 	}
 	return repo, nil
 }
 
 //The downloadV1Repository method retrieves file from provided url, unmarshalls it to V1 and returns obtained V1 struct.
 func downloadV1Repository(url string) (*V1, error) {
+	body, err := download(url)
+	if err != nil {
+		return nil, err
+	}
+	r, err := parseV1Repository(url, body)
+	if err != nil {
+		logger.Error().Msgf("downloaded repository is not valid:\n%s", err.Error())
+		return nil, err
+	}
+	return r, nil
+}
+
+//download retrieves non empty content from provided url
+func download(url string) ([]byte, error) {
 	logger.Trace().Msgf("will try to download repo from: %s", url)
 	res, err := httpClient.Get(url)
 	if err != nil {
 		logger.Error().Err(err).Msg("wasn't able to perform http GET on repo URL")
 		return nil, err
 	}
-	if res.StatusCode == 404 {
-		err2 := fmt.Errorf("repository %s not found", url)
-		logger.Warn().Err(err2).Msg("not found")
-		return nil, err2
-	}
 	if res.Body != nil {
 		defer func(b io.ReadCloser) {
 			_ = b.Close()
 		}(res.Body)
 	}
+	if res.StatusCode == 404 {
+		err2 := fmt.Errorf("repository %s %w", url, errNotFound)
+		logger.Warn().Err(err2).Msg("not found")
+		return nil, err2
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s of %s", res.Status, url)
+	}
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
 		logger.Error().Err(err).Msg("wasn't able to read response body")
@@ -275,30 +489,15 @@ func downloadV1Repository(url string) (*V1, error) {
 		logger.Error().Err(err).Msgf("got nothing from: %s", url)
 		return nil, err
 	}
-	var r *V1
-	err = yaml.Unmarshal(body, &r)
-	if err != nil {
-		logger.Error().Err(err).Msg("wasn't able to unmarshal body into correct yaml")
-		return nil, err
-	}
-	return r, nil
+	return body, nil
 }
 
-func inferRepoName(repo string) string {
-	u, err := url.Parse(repo)
-	if err != nil {
-		logger.Fatal().Err(err).Msgf("url.Parse(%s) failed", repo)
-	}
-	logger.Debug().Msgf("url.Parse(%s) Path: %s", repo, u.Path)
-	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
-	np := u.Path
-	if np[0] == '/' {
-		np = np[1:]
-	}
-	return reg.ReplaceAllString(np, "-")
+//repoFilePath returns path of named repository file
+func (r *Repository) repoFilePath(repoName string) string {
+	return path.Join(r.directory, repoName+".yaml")
 }
 
-func persistV1RepositoryFile(inferredRepoName string, v1 *V1, force bool) error {
+func (r *Repository) persistV1RepositoryFile(inferredRepoName string, v1 *V1, force bool) error {
 	if v1 == nil {
 		err := errors.New("nil repository")
 		logger.Error().Err(err).Msg("incorrect nil parameter")
@@ -309,7 +508,7 @@ func persistV1RepositoryFile(inferredRepoName string, v1 *V1, force bool) error
 		logger.Error().Err(err).Msg("wasn't able to marshal repo object into yaml")
 		return err
 	}
-	filePath := path.Join(util.UsedReposDirectory, inferredRepoName+".yaml")
+	filePath := r.repoFilePath(inferredRepoName)
 	if _, err = os.Stat(filePath); err == nil {
 		logger.Debug().Msg("file " + filePath + " already exists")
 		if !force {
@@ -317,5 +516,28 @@ func persistV1RepositoryFile(inferredRepoName string, v1 *V1, force bool) error
 		}
 	}
 	logger.Debug().Msgf("will write yaml to file: %s", filePath)
-	return ioutil.WriteFile(filePath, b, 0644)
+	return writeFile(filePath, b)
+}
+
+//writeFile replaces file content atomically so that concurrent readers never see partially written file
+func writeFile(filePath string, content []byte) error {
+	f, err := ioutil.TempFile(path.Dir(filePath), "."+path.Base(filePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(f.Name())
+	}()
+	_, err = f.Write(content)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(f.Name(), 0644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filePath)
 }
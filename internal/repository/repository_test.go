@@ -1,13 +1,17 @@
 package repository
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/epiphany-platform/cli/internal/util"
 
@@ -22,6 +26,7 @@ func setup(a *assert.Assertions) (string, string) {
 	a.NoError(err)
 	reposDirectory, err := ioutil.TempDir(mainDirectory, "*-"+util.DefaultRepoDirectoryName)
 	a.NoError(err)
+	util.UsedTrustedKeysFile = path.Join(mainDirectory, util.DefaultTrustedKeysFileName)
 	return mainDirectory, reposDirectory
 }
 
@@ -46,11 +51,38 @@ func Test_inferRepoName(t *testing.T) {
 			repoName: "https://github.com/mkyc/my-epiphany-repo",
 			want:     "mkyc-my-epiphany-repo",
 		},
+		{
+			name:     "file",
+			repoName: "file:///srv/mirror/modules/v1.yaml",
+			want:     "srv-mirror-modules",
+		},
+		{
+			name:     "file with custom name",
+			repoName: "file:///srv/mirror/my-repo.yml",
+			want:     "srv-mirror-my-repo",
+		},
+		{
+			name:     "http",
+			repoName: "https://git.example.com/raw/team/modules/v1.yaml?ref=main",
+			want:     "raw-team-modules",
+		},
+		{
+			name:     "git scp-like",
+			repoName: "git@git.example.com:team/modules.git",
+			want:     "team-modules",
+		},
+		{
+			name:     "git https",
+			repoName: "git+https://git.example.com/team/modules",
+			want:     "team-modules",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := assert.New(t)
-			a.Equal(inferRepoName(tt.repoName), tt.want)
+			s, err := ParseSource(tt.repoName, "")
+			a.NoError(err)
+			a.Equal(tt.want, inferRepoName(s))
 		})
 	}
 }
@@ -148,7 +180,7 @@ components: []
 				err := ioutil.WriteFile(path.Join(util.UsedReposDirectory, tt.args.inferredRepoName+".yaml"), tt.mocked, 0644)
 				a.NoError(err)
 			}
-			err := persistV1RepositoryFile(tt.args.inferredRepoName, tt.args.v1, tt.args.force)
+			err := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile)).persistV1RepositoryFile(tt.args.inferredRepoName, tt.args.v1, tt.args.force)
 			if tt.wantErr {
 				a.Error(err)
 			} else {
@@ -184,8 +216,8 @@ func Test_downloadV1Repository(t *testing.T) {
 				url: fmt.Sprintf("/%s/%s/%s", "test-user/test-repo", util.DefaultRepositoryBranch, util.DefaultV1RepositoryFileName),
 			},
 			want: &V1{
-				Version:    "v",
-				Kind:       "k",
+				Version:    "v1",
+				Kind:       "Repository",
 				Name:       "n",
 				Components: []Component{},
 			},
@@ -207,8 +239,8 @@ func Test_downloadV1Repository(t *testing.T) {
 			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
 				t.Logf("got test request to: %s", req.URL.String())
 				if req.URL.String() == "/test-user/test-repo/HEAD/v1.yaml" {
-					_, _ = rw.Write([]byte(`version: v
-kind: k
+					_, _ = rw.Write([]byte(`version: v1
+kind: Repository
 name: n
 components: []
 `))
@@ -317,7 +349,7 @@ components: []
 	}
 }
 
-func Test_load(t *testing.T) {
+func TestRepository_snapshot(t *testing.T) {
 	tests := []struct {
 		name    string
 		mocked  map[string][]byte
@@ -389,13 +421,285 @@ components: []
 					a.NoError(err)
 				}
 			}
-			err := load()
+			idx, err := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile)).snapshot()
 			if tt.wantErr {
 				a.Error(err)
 			} else {
 				a.NoError(err)
-				a.Len(loaded.v1s, tt.wantLen)
+				a.Len(idx.repositories(), tt.wantLen)
 			}
 		})
 	}
 }
+
+func TestRepository_GetModule(t *testing.T) {
+	repoFile := `version: v1
+kind: Repository
+name: %s
+components:
+  - name: c1
+    type: docker
+    versions:
+      - version: 0.1.0
+        image: "docker.io/hashicorp/terraform:0.12.28"
+      - version: 0.2.0
+        latest: true
+        image: "docker.io/hashicorp/terraform:0.13.0"
+`
+	tests := []struct {
+		name        string
+		mocked      map[string][]byte
+		repoName    string
+		moduleName  string
+		version     string
+		wantVersion string
+		wantErr     bool
+	}{
+		{
+			name: "happy path",
+			mocked: map[string][]byte{
+				"first-repo.yaml": []byte(fmt.Sprintf(repoFile, "first")),
+			},
+			moduleName:  "c1",
+			wantVersion: "0.2.0",
+			wantErr:     false,
+		},
+		{
+			name: "multiple repositories without repository name",
+			mocked: map[string][]byte{
+				"first-repo.yaml":  []byte(fmt.Sprintf(repoFile, "first")),
+				"second-repo.yaml": []byte(fmt.Sprintf(repoFile, "second")),
+			},
+			moduleName: "c1",
+			wantErr:    true,
+		},
+		{
+			name: "multiple repositories with repository name",
+			mocked: map[string][]byte{
+				"first-repo.yaml":  []byte(fmt.Sprintf(repoFile, "first")),
+				"second-repo.yaml": []byte(fmt.Sprintf(repoFile, "second")),
+			},
+			repoName:    "second",
+			moduleName:  "c1",
+			wantVersion: "0.2.0",
+			wantErr:     false,
+		},
+		{
+			name: "exact version",
+			mocked: map[string][]byte{
+				"first-repo.yaml": []byte(fmt.Sprintf(repoFile, "first")),
+			},
+			repoName:    "first",
+			moduleName:  "c1",
+			version:     "0.1.0",
+			wantVersion: "0.1.0",
+			wantErr:     false,
+		},
+		{
+			name: "version range",
+			mocked: map[string][]byte{
+				"first-repo.yaml": []byte(fmt.Sprintf(repoFile, "first")),
+			},
+			repoName:    "first",
+			moduleName:  "c1",
+			version:     "~0.1",
+			wantVersion: "0.1.0",
+			wantErr:     false,
+		},
+		{
+			name: "not matching version range",
+			mocked: map[string][]byte{
+				"first-repo.yaml": []byte(fmt.Sprintf(repoFile, "first")),
+			},
+			repoName:   "first",
+			moduleName: "c1",
+			version:    "^1.0",
+			wantErr:    false,
+		},
+		{
+			name: "incorrect version range",
+			mocked: map[string][]byte{
+				"first-repo.yaml": []byte(fmt.Sprintf(repoFile, "first")),
+			},
+			repoName:   "first",
+			moduleName: "c1",
+			version:    "~>incorrect",
+			wantErr:    true,
+		},
+		{
+			name: "missing module",
+			mocked: map[string][]byte{
+				"first-repo.yaml": []byte(fmt.Sprintf(repoFile, "first")),
+			},
+			moduleName: "c2",
+			wantErr:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+			defer func() {
+				_ = os.RemoveAll(util.UsedConfigurationDirectory)
+			}()
+
+			for k, v := range tt.mocked {
+				err := ioutil.WriteFile(path.Join(util.UsedReposDirectory, k), v, 0644)
+				a.NoError(err)
+			}
+			got, err := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile)).GetModule(tt.repoName, tt.moduleName, tt.version)
+			if tt.wantErr {
+				a.Error(err)
+				return
+			}
+			a.NoError(err)
+			if tt.wantVersion == "" {
+				a.Nil(got)
+			} else if a.NotNil(got) {
+				a.Equal(tt.wantVersion, got.Version)
+				a.Equal(tt.moduleName, got.Name)
+				a.Equal("docker", got.Type)
+			}
+		})
+	}
+}
+
+func TestRepository_Install_AlreadyInstalled(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	filePath := path.Join(util.UsedConfigurationDirectory, "r.yaml")
+	a.NoError(ioutil.WriteFile(filePath, []byte("version: v1\nkind: Repository\ncomponents: []\n"), 0644))
+
+	r := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile))
+
+	a.NoError(r.Install("file://"+filePath, false, "", true))
+	err := r.Install("file://"+filePath, false, "", true)
+	a.True(errors.Is(err, ErrAlreadyInstalled))
+	a.NoError(r.Install("file://"+filePath, true, "", true))
+}
+
+func TestRepository_Update(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	repository := func(versions ...string) []byte {
+		b := bytes.NewBufferString("version: v1\nkind: Repository\nname: declared\ncomponents:\n- name: c1\n  type: docker\n  versions:\n")
+		for _, v := range versions {
+			b.WriteString(fmt.Sprintf("  - version: %s\n    image: ubuntu\n", v))
+		}
+		return b.Bytes()
+	}
+	r := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile))
+	filePath := path.Join(util.UsedConfigurationDirectory, "r.yaml")
+	a.NoError(ioutil.WriteFile(filePath, repository("0.1.0", "0.2.0"), 0644))
+	a.NoError(r.Install("file://"+filePath, false, "", true))
+	name := inferRepoName(Source{Type: SourceFile, Origin: filePath})
+	installed, err := r.GetMetadata(name)
+	a.NoError(err)
+
+	changes, err := r.Update(name, true)
+	a.NoError(err)
+	a.True(changes.IsEmpty())
+
+	a.NoError(ioutil.WriteFile(filePath, repository("0.2.0", "0.3.0", "0.4.0"), 0644))
+	changes, err = r.Update("declared", true)
+	a.NoError(err)
+	a.Equal(&Changes{Added: []string{"c1:0.3.0", "c1:0.4.0"}, Removed: []string{"c1:0.1.0"}}, changes)
+	updated, err := r.GetMetadata(name)
+	a.NoError(err)
+	a.Equal(installed.Source, updated.Source)
+	a.NotEqual(installed.Hash, updated.Hash)
+	m, err := r.GetModule("declared", "c1", "0.4.0")
+	a.NoError(err)
+	a.NotNil(m)
+
+	all, err := r.UpdateAll(true)
+	a.NoError(err)
+	a.Equal(map[string]*Changes{name: {}}, all)
+
+	_, err = r.Update("missing", true)
+	a.EqualError(err, "repository missing is not installed")
+
+	a.NoError(r.Remove("declared"))
+	a.NoFileExists(r.repoFilePath(name))
+	a.NoFileExists(r.metadataFilePath(name))
+	a.EqualError(r.Remove(name), fmt.Sprintf("repository %s is not installed", name))
+}
+
+func TestRepository_index(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	write := func(fileName, version string, modTime time.Time) {
+		filePath := path.Join(util.UsedReposDirectory, fileName)
+		content := fmt.Sprintf("version: v1\nkind: Repository\nname: %s\ncomponents:\n- name: c1\n  type: docker\n  versions:\n  - version: %s\n    image: ubuntu\n", fileName, version)
+		a.NoError(ioutil.WriteFile(filePath, []byte(content), 0644))
+		a.NoError(os.Chtimes(filePath, modTime, modTime))
+	}
+	r := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile))
+	modTime := time.Now().Add(-time.Hour)
+	write("r1.yaml", "0.1.0", modTime)
+
+	first, err := r.snapshot()
+	a.NoError(err)
+	second, err := r.snapshot()
+	a.NoError(err)
+	a.Same(first, second)
+
+	write("r1.yaml", "0.2.0", modTime.Add(time.Second))
+	write("r2.yaml", "0.3.0", modTime)
+	m, err := r.GetModule("r1.yaml", "c1", "")
+	a.NoError(err)
+	a.Equal("0.2.0", m.Version)
+	third, err := r.snapshot()
+	a.NoError(err)
+	a.NotSame(second, third)
+	a.Equal([]string{"r1", "r2"}, third.names)
+	a.Contains(third.versions, indexKey{repo: "r2.yaml", component: "c1", version: "0.3.0"})
+
+	a.NoError(os.Remove(path.Join(util.UsedReposDirectory, "r2.yaml")))
+	found, err := r.Search(SearchOptions{Query: "c1"})
+	a.NoError(err)
+	if a.Len(found, 1) {
+		a.Equal("r1.yaml", found[0].Repository)
+	}
+}
+
+func TestRepository_concurrent(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	filePath := path.Join(util.UsedConfigurationDirectory, "r.yaml")
+	a.NoError(ioutil.WriteFile(filePath, []byte("version: v1\nkind: Repository\nname: r\ncomponents:\n- name: c1\n  type: docker\n  versions:\n  - version: 0.1.0\n    image: ubuntu\n"), 0644))
+	r := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := r.GetModule("", "c1", "")
+			a.NoError(err)
+			_, err = r.List()
+			a.NoError(err)
+		}()
+		go func() {
+			defer wg.Done()
+			err := r.Install("file://"+filePath, true, "", true)
+			a.NoError(err)
+		}()
+	}
+	wg.Wait()
+	m, err := r.GetModule("r", "c1", "")
+	a.NoError(err)
+	a.Equal("0.1.0", m.Version)
+}
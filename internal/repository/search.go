@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/docker/distribution/reference"
+)
+
+//score values of different kinds of match, the better match the higher score
+const (
+	scoreExactName   = 100
+	scoreNamePrefix  = 80
+	scoreName        = 60
+	scoreTag         = 50
+	scoreDescription = 40
+	scoreFuzzyName   = 20
+)
+
+//SearchOptions struct contains query and filters used by Repository.Search. Empty fields are not used.
+type SearchOptions struct {
+	Query      string // matched against component name (also fuzzily), description and tags
+	Type       string // component type
+	Registry   string // registry of image, "docker.io" for images without registry
+	Repository string // name of repository
+	Tag        string // tag which component has to have
+}
+
+//SearchResult struct contains component found by Repository.Search with versions matching filters ordered
+//latest first
+type SearchResult struct {
+	Repository string
+	Component  Component
+	Score      int
+}
+
+//Search finds components matching query and filters. Results are ordered by score of query match, then by
+//repository and component name.
+func (r *Repository) Search(options SearchOptions) ([]SearchResult, error) {
+	idx, err := r.snapshot()
+	if err != nil {
+		return nil, err
+	}
+	query := strings.ToLower(strings.TrimSpace(options.Query))
+	var result []SearchResult
+	for _, v1 := range idx.repositories() {
+		if options.Repository != "" && options.Repository != v1.Name {
+			continue
+		}
+		for _, c := range v1.Components {
+			if options.Type != "" && options.Type != c.Type {
+				continue
+			}
+			if options.Tag != "" && !containsFold(c.Tags, options.Tag) {
+				continue
+			}
+			score := matchScore(c, query)
+			if score == 0 {
+				continue
+			}
+			var versions []ComponentVersion
+			for _, v := range c.Versions {
+				if options.Registry == "" || strings.EqualFold(registry(c.Type, v.Image), options.Registry) {
+					versions = append(versions, v)
+				}
+			}
+			if len(versions) == 0 {
+				continue
+			}
+			sortVersions(versions)
+			found := c
+			found.Versions = versions
+			result = append(result, SearchResult{Repository: v1.Name, Component: found, Score: score})
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+		if result[i].Repository != result[j].Repository {
+			return result[i].Repository < result[j].Repository
+		}
+		return result[i].Component.Name < result[j].Component.Name
+	})
+	return result, nil
+}
+
+//matchScore returns score of best match of lower cased query in component or 0 if component does not match.
+//Empty query matches every component.
+func matchScore(c Component, query string) int {
+	if query == "" {
+		return 1
+	}
+	name := strings.ToLower(c.Name)
+	switch {
+	case name == query:
+		return scoreExactName
+	case strings.HasPrefix(name, query):
+		return scoreNamePrefix
+	case strings.Contains(name, query):
+		return scoreName
+	}
+	for _, t := range c.Tags {
+		if strings.Contains(strings.ToLower(t), query) {
+			return scoreTag
+		}
+	}
+	if strings.Contains(strings.ToLower(c.Description), query) {
+		return scoreDescription
+	}
+	return fuzzyScore(name, query)
+}
+
+//fuzzyScore checks if all characters of query appear in s in the same order. The fewer characters are skipped
+//between them the higher score is returned (at most scoreFuzzyName). If query does not match 0 is returned.
+func fuzzyScore(s, query string) int {
+	qs := []rune(query)
+	matched, gaps, start := 0, 0, -1
+	for i, c := range []rune(s) {
+		if matched == len(qs) {
+			break
+		}
+		if c == qs[matched] {
+			if start >= 0 {
+				gaps += i - start - 1
+			}
+			start = i
+			matched++
+		}
+	}
+	if matched < len(qs) {
+		return 0
+	}
+	if gaps >= scoreFuzzyName {
+		return 1
+	}
+	return scoreFuzzyName - gaps
+}
+
+//registry returns registry of docker image or empty string for other component types and invalid images
+func registry(componentType, image string) string {
+	if componentType != "docker" {
+		return ""
+	}
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return ""
+	}
+	return reference.Domain(named)
+}
+
+//sortVersions orders versions latest first, then by semantic version descending. Versions which are not semantic
+//versions go last in original order.
+func sortVersions(versions []ComponentVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		if versions[i].IsLatest != versions[j].IsLatest {
+			return versions[i].IsLatest
+		}
+		vi, errI := semver.NewVersion(versions[i].Version)
+		vj, errJ := semver.NewVersion(versions[j].Version)
+		if errI != nil || errJ != nil {
+			return errI == nil && errJ != nil
+		}
+		return vi.GreaterThan(vj)
+	})
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
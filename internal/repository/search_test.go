@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_Search(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a.NoError(ioutil.WriteFile(path.Join(util.UsedReposDirectory, "r1.yaml"), []byte(`version: v1
+kind: Repository
+name: r1
+components:
+- name: terraform
+  type: docker
+  description: runs terraform
+  tags: [iac]
+  versions:
+  - version: 0.1.0
+    image: docker.io/hashicorp/terraform:0.12.28
+  - version: 0.3.0
+    image: docker.io/hashicorp/terraform:0.13.0
+  - version: 0.2.0
+    latest: true
+    image: registry.example.com/terraform:0.12.29
+- name: azbi
+  type: docker
+  description: Azure basic infrastructure
+  tags: [azure, iac]
+  versions:
+  - version: 0.1.0
+    image: docker.io/epiphanyplatform/azbi:0.1.0
+`), 0644))
+	a.NoError(ioutil.WriteFile(path.Join(util.UsedReposDirectory, "r2.yaml"), []byte(`version: v1
+kind: Repository
+name: r2
+components:
+- name: terraform-azure
+  type: local
+  description: terraform from PATH
+  versions:
+  - version: 1.0.0
+    image: terraform
+`), 0644))
+	r := New(util.UsedReposDirectory, NewTrustStore(util.UsedTrustedKeysFile))
+
+	type found struct {
+		name     string
+		versions []string
+	}
+	tests := []struct {
+		name    string
+		options SearchOptions
+		want    []found
+	}{
+		{
+			name:    "exact name ranked first, latest version first",
+			options: SearchOptions{Query: "terraform"},
+			want: []found{
+				{name: "r1/terraform", versions: []string{"0.2.0", "0.3.0", "0.1.0"}},
+				{name: "r2/terraform-azure", versions: []string{"1.0.0"}},
+			},
+		},
+		{
+			name:    "substring of name before description",
+			options: SearchOptions{Query: "AZURE"},
+			want: []found{
+				{name: "r2/terraform-azure", versions: []string{"1.0.0"}},
+				{name: "r1/azbi", versions: []string{"0.1.0"}},
+			},
+		},
+		{
+			name:    "tag",
+			options: SearchOptions{Query: "iac"},
+			want: []found{
+				{name: "r1/azbi", versions: []string{"0.1.0"}},
+				{name: "r1/terraform", versions: []string{"0.2.0", "0.3.0", "0.1.0"}},
+			},
+		},
+		{
+			name:    "fuzzy",
+			options: SearchOptions{Query: "tfm"},
+			want: []found{
+				{name: "r1/terraform", versions: []string{"0.2.0", "0.3.0", "0.1.0"}},
+				{name: "r2/terraform-azure", versions: []string{"1.0.0"}},
+			},
+		},
+		{
+			name:    "no match",
+			options: SearchOptions{Query: "kubernetes"},
+		},
+		{
+			name:    "type filter",
+			options: SearchOptions{Type: "local"},
+			want: []found{
+				{name: "r2/terraform-azure", versions: []string{"1.0.0"}},
+			},
+		},
+		{
+			name:    "registry filter",
+			options: SearchOptions{Query: "terraform", Registry: "docker.io"},
+			want: []found{
+				{name: "r1/terraform", versions: []string{"0.3.0", "0.1.0"}},
+			},
+		},
+		{
+			name:    "repository and tag filter",
+			options: SearchOptions{Repository: "r1", Tag: "azure"},
+			want: []found{
+				{name: "r1/azbi", versions: []string{"0.1.0"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			results, err := r.Search(tt.options)
+			a.NoError(err)
+			var got []found
+			for _, result := range results {
+				f := found{name: result.Repository + "/" + result.Component.Name}
+				for _, v := range result.Component.Versions {
+					f.versions = append(f.versions, v.Version)
+				}
+				got = append(got, f)
+			}
+			a.Equal(tt.want, got)
+		})
+	}
+}
+
+func Test_fuzzyScore(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		query string
+		want  int
+	}{
+		{name: "adjacent", s: "terraform", query: "ter", want: scoreFuzzyName},
+		{name: "gaps", s: "terraform", query: "tfm", want: scoreFuzzyName - 6},
+		{name: "wrong order", s: "terraform", query: "mft", want: 0},
+		{name: "longer than s", s: "tf", query: "tfm", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, fuzzyScore(tt.s, tt.query))
+		})
+	}
+}
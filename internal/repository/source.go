@@ -0,0 +1,293 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	SourceGithub = "github" // "user-name/repo-name" shorthand of GitHub repository
+	SourceFile   = "file"   // local repository file or directory containing it
+	SourceHttp   = "http"   // plain URL of repository file
+	SourceGit    = "git"    // any Git remote containing repository file in its root
+)
+
+//Source struct describes where repository was installed from
+type Source struct {
+	Type   string `yaml:"type"`
+	Origin string `yaml:"origin"`
+	Branch string `yaml:"branch,omitempty"`
+}
+
+//ParseSource recognizes type of repository source. Supported forms are:
+// user-name/repo-name or https://github.com/user-name/repo-name - GitHub repository
+// file:///path/to/v1.yaml or file:///path/to/directory - local repository file
+// https://host/path/to/v1.yaml - repository file available under plain URL
+// git@host:path.git, ssh://..., git://..., https://host/path.git or git+https://host/path - Git remote
+//Branch is used only by GitHub and Git sources.
+func ParseSource(repo, branch string) (Source, error) {
+	if repo == "" {
+		return Source{}, errors.New("empty repository source")
+	}
+	s := Source{Origin: repo, Branch: branch}
+	switch {
+	case strings.HasPrefix(repo, "file://"):
+		s.Type = SourceFile
+		s.Origin = strings.TrimPrefix(repo, "file://")
+		if s.Origin == "" {
+			return Source{}, fmt.Errorf("file source %s has no path", repo)
+		}
+		s.Branch = ""
+	case strings.HasPrefix(repo, "git+"):
+		s.Type = SourceGit
+		s.Origin = strings.TrimPrefix(repo, "git+")
+	case strings.HasPrefix(repo, "git@"), strings.HasPrefix(repo, "ssh://"), strings.HasPrefix(repo, "git://"),
+		strings.HasSuffix(repo, ".git"):
+		s.Type = SourceGit
+	case strings.HasPrefix(repo, "https://"), strings.HasPrefix(repo, "http://"):
+		u, err := url.Parse(repo)
+		if err != nil {
+			return Source{}, err
+		}
+		if u.Host == "github.com" {
+			s.Type = SourceGithub
+			s.Origin = strings.Trim(u.Path, "/")
+		} else {
+			s.Type = SourceHttp
+			s.Branch = ""
+		}
+	default:
+		s.Type = SourceGithub
+	}
+	if s.Type == SourceGithub && strings.Count(s.Origin, "/") != 1 {
+		return Source{}, fmt.Errorf("GitHub repository %s needs to have 'user-name/repo-name' format", repo)
+	}
+	if s.Branch == "" && (s.Type == SourceGithub || s.Type == SourceGit) {
+		s.Branch = util.DefaultRepositoryBranch
+	}
+	return s, nil
+}
+
+func (s Source) String() string {
+	if s.Branch != "" {
+		return fmt.Sprintf("%s %s (%s)", s.Type, s.Origin, s.Branch)
+	}
+	return fmt.Sprintf("%s %s", s.Type, s.Origin)
+}
+
+//fetched struct contains repository file and its detached signature retrieved from source
+type fetched struct {
+	location  string
+	content   []byte
+	signature []byte // nil if there is no signature next to repository file
+}
+
+//fetch retrieves repository file from source together with its signature (repository file name with ".sig"
+//extension) if there is one
+func (s Source) fetch() (*fetched, error) {
+	logger.Debug().Msgf("will try to fetch repository from %s", s)
+	f := &fetched{}
+	var err error
+	switch s.Type {
+	case SourceGithub:
+		f.location = fmt.Sprintf("%s/%s/%s/%s", util.GithubUrl, s.Origin, s.Branch, util.DefaultV1RepositoryFileName)
+		f.content, err = download(f.location)
+		if err == nil {
+			f.signature, err = downloadSignature(f.location)
+		}
+	case SourceHttp:
+		f.location = s.Origin
+		f.content, err = download(f.location)
+		if err == nil {
+			f.signature, err = downloadSignature(f.location)
+		}
+	case SourceFile:
+		f.location = s.Origin
+		info, err2 := os.Stat(f.location)
+		if err2 != nil {
+			return nil, err2
+		}
+		if info.IsDir() {
+			f.location = path.Join(f.location, util.DefaultV1RepositoryFileName)
+		}
+		f.content, err = ioutil.ReadFile(f.location)
+		if err == nil {
+			f.signature, err = ioutil.ReadFile(f.location + signatureFileExtension)
+			if os.IsNotExist(err) {
+				f.signature, err = nil, nil
+			}
+		}
+	case SourceGit:
+		f.location = s.Origin + ":" + util.DefaultV1RepositoryFileName
+		var files [][]byte
+		files, err = clone(s.Origin, s.Branch, util.DefaultV1RepositoryFileName, util.DefaultV1RepositoryFileName+signatureFileExtension)
+		if err == nil {
+			f.content, f.signature = files[0], files[1]
+			if f.content == nil {
+				err = fmt.Errorf("repository %s not found", f.location)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown repository source type %s", s.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+//downloadSignature retrieves signature of repository file available under url. Missing signature is not an error.
+func downloadSignature(location string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	u.Path += signatureFileExtension
+	u.RawPath = ""
+	signature, err := download(u.String())
+	if errors.Is(err, errNotFound) {
+		logger.Debug().Msgf("there is no signature of %s", location)
+		return nil, nil
+	}
+	return signature, err
+}
+
+//retrieve fetches repository from source and verifies its signature (see Repository.verify). Fetched repository is
+//returned together with its Metadata.
+func (r *Repository) retrieve(s Source, allowUnsigned bool) (*V1, Metadata, error) {
+	f, err := s.fetch()
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	signedBy, err := r.verify(f, allowUnsigned)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	v1, err := parseV1Repository(f.location, f.content)
+	if err != nil {
+		return nil, Metadata{}, err
+	}
+	m := newMetadata(s, f.content)
+	m.SignedBy = signedBy
+	return v1, m, nil
+}
+
+//clone makes shallow clone of Git remote to temporary directory and returns contents of files from its root. Content
+//of missing file is nil.
+func clone(origin, branch string, fileNames ...string) ([][]byte, error) {
+	dir, err := ioutil.TempDir("", "e-repository-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if branch != "" && branch != util.DefaultRepositoryBranch {
+		args = append(args, "--branch", branch)
+	}
+	args = append(args, "--", origin, dir)
+	logger.Trace().Msgf("will run git %s", strings.Join(args, " "))
+	cmd := exec.Command("git", args...)
+	// never wait for credentials typed in terminal, configured credential helpers still work
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("git clone of %s failed: %w: %s", origin, err, strings.TrimSpace(string(out)))
+	}
+	result := make([][]byte, len(fileNames))
+	for i, fileName := range fileNames {
+		result[i], err = ioutil.ReadFile(path.Join(dir, fileName))
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+//Metadata struct describes provenance of installed repository
+type Metadata struct {
+	Source    `yaml:",inline"`
+	FetchedAt time.Time `yaml:"fetchedAt"`
+	Hash      string    `yaml:"hash"`               // sha256 of fetched repository file
+	SignedBy  string    `yaml:"signedBy,omitempty"` // name of trusted key repository file is signed with
+}
+
+//newMetadata returns metadata of repository content fetched from source just now
+func newMetadata(s Source, content []byte) Metadata {
+	return Metadata{
+		Source:    s,
+		FetchedAt: time.Now().UTC().Truncate(time.Second),
+		Hash:      fmt.Sprintf("sha256:%x", sha256.Sum256(content)),
+	}
+}
+
+//metadataFilePath returns path of file storing metadata of named repository next to repository file
+func (r *Repository) metadataFilePath(repoName string) string {
+	return path.Join(r.directory, repoName+util.DefaultRepoMetadataFileExtension)
+}
+
+//persistMetadata saves metadata of named repository
+func (r *Repository) persistMetadata(repoName string, m Metadata) error {
+	b, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	logger.Debug().Msgf("will write metadata of repository %s to file %s", repoName, r.metadataFilePath(repoName))
+	return writeFile(r.metadataFilePath(repoName), b)
+}
+
+//GetMetadata returns metadata of named repository recorded when it was installed or updated
+func (r *Repository) GetMetadata(repoName string) (*Metadata, error) {
+	b, err := ioutil.ReadFile(r.metadataFilePath(repoName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no metadata recorded for repository %s (install it again with '--force' to record it)", repoName)
+		}
+		return nil, err
+	}
+	m := &Metadata{}
+	err = yaml.Unmarshal(b, m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+//inferRepoName builds repository name from path of its source skipping repository file name and extensions
+func inferRepoName(s Source) string {
+	p := s.Origin
+	switch s.Type {
+	case SourceGit:
+		if i := strings.Index(p, ":"); strings.HasPrefix(p, "git@") && i > 0 {
+			p = p[i+1:]
+		} else if u, err := url.Parse(p); err == nil {
+			p = u.Path
+		}
+		p = strings.TrimSuffix(strings.TrimSuffix(p, "/"), ".git")
+	case SourceHttp:
+		if u, err := url.Parse(p); err == nil {
+			p = u.Path
+		}
+	}
+	if filepath.Base(p) == util.DefaultV1RepositoryFileName {
+		p = path.Dir(p)
+	} else if ext := path.Ext(p); ext == ".yaml" || ext == ".yml" {
+		p = strings.TrimSuffix(p, ext)
+	}
+	return strings.Trim(nonAlphanumericRegexp.ReplaceAllString(p, "-"), "-")
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sourceTestRepository = `version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    latest: true
+    image: docker.io/hashicorp/terraform:0.12.28
+`
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    string
+		branch  string
+		want    Source
+		wantErr error
+	}{
+		{
+			name: "github shorthand",
+			repo: "epiphany-platform/modules",
+			want: Source{Type: SourceGithub, Origin: "epiphany-platform/modules", Branch: util.DefaultRepositoryBranch},
+		},
+		{
+			name:   "github url",
+			repo:   "https://github.com/epiphany-platform/modules/",
+			branch: "develop",
+			want:   Source{Type: SourceGithub, Origin: "epiphany-platform/modules", Branch: "develop"},
+		},
+		{
+			name:    "github incorrect",
+			repo:    "modules",
+			wantErr: errors.New("GitHub repository modules needs to have 'user-name/repo-name' format"),
+		},
+		{
+			name:   "file",
+			repo:   "file:///srv/mirror/v1.yaml",
+			branch: "ignored",
+			want:   Source{Type: SourceFile, Origin: "/srv/mirror/v1.yaml"},
+		},
+		{
+			name:    "file without path",
+			repo:    "file://",
+			wantErr: errors.New("file source file:// has no path"),
+		},
+		{
+			name: "http",
+			repo: "https://git.example.com/raw/team/modules/main/v1.yaml",
+			want: Source{Type: SourceHttp, Origin: "https://git.example.com/raw/team/modules/main/v1.yaml"},
+		},
+		{
+			name:   "git scp-like",
+			repo:   "git@git.example.com:team/modules.git",
+			branch: "main",
+			want:   Source{Type: SourceGit, Origin: "git@git.example.com:team/modules.git", Branch: "main"},
+		},
+		{
+			name: "git https",
+			repo: "https://git.example.com/team/modules.git",
+			want: Source{Type: SourceGit, Origin: "https://git.example.com/team/modules.git", Branch: util.DefaultRepositoryBranch},
+		},
+		{
+			name: "git explicit",
+			repo: "git+https://git.example.com/team/modules",
+			want: Source{Type: SourceGit, Origin: "https://git.example.com/team/modules", Branch: util.DefaultRepositoryBranch},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := ParseSource(tt.repo, tt.branch)
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			a.NoError(err)
+			a.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestInstall_Sources(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+
+	// every source is signed with trusted key
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	a.NoError(err)
+	trust := NewTrustStore(util.UsedTrustedKeysFile)
+	_, err = trust.Add("publisher", []byte(base64.StdEncoding.EncodeToString(publicKey)))
+	a.NoError(err)
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(sourceTestRepository)))
+	writeSigned := func(directory string) {
+		a.NoError(ioutil.WriteFile(path.Join(directory, util.DefaultV1RepositoryFileName), []byte(sourceTestRepository), 0644))
+		a.NoError(ioutil.WriteFile(path.Join(directory, util.DefaultV1RepositoryFileName+".sig"), []byte(signature), 0644))
+	}
+
+	// local file
+	fileDirectory := path.Join(util.UsedConfigurationDirectory, "mirror")
+	a.NoError(os.MkdirAll(fileDirectory, 0755))
+	writeSigned(fileDirectory)
+
+	// plain URL
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/team/modules/v1.yaml":
+			_, _ = rw.Write([]byte(sourceTestRepository))
+			return
+		case "/team/modules/v1.yaml.sig":
+			_, _ = rw.Write([]byte(signature))
+			return
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	httpClient = server.Client()
+
+	// git remote with repository file on non-default branch
+	gitDirectory := path.Join(util.UsedConfigurationDirectory, "git", "team", "modules")
+	a.NoError(os.MkdirAll(gitDirectory, 0755))
+	writeSigned(gitDirectory)
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"checkout", "--quiet", "-b", "mirror"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "-m", "repository"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = gitDirectory
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Skipf("git is not usable: %v: %s", err, out)
+		}
+	}
+
+	r := New(util.UsedReposDirectory, trust)
+
+	tests := []struct {
+		name     string
+		repo     string
+		branch   string
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name:     "file",
+			repo:     "file://" + fileDirectory,
+			wantName: inferRepoName(Source{Type: SourceFile, Origin: fileDirectory}),
+		},
+		{
+			name:     "http",
+			repo:     server.URL + "/team/modules/v1.yaml",
+			wantName: "team-modules",
+		},
+		{
+			name:    "http not found",
+			repo:    server.URL + "/team/missing/v1.yaml",
+			wantErr: true,
+		},
+		{
+			name:     "git",
+			repo:     "git+file://" + gitDirectory,
+			branch:   "mirror",
+			wantName: inferRepoName(Source{Type: SourceGit, Origin: "file://" + gitDirectory}),
+		},
+		{
+			name:    "git missing branch",
+			repo:    "git+file://" + gitDirectory,
+			branch:  "missing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			err := r.Install(tt.repo, true, tt.branch, false)
+			if tt.wantErr {
+				a.Error(err)
+				return
+			}
+			a.NoError(err)
+			a.FileExists(path.Join(util.UsedReposDirectory, tt.wantName+".yaml"))
+			want, err := ParseSource(tt.repo, tt.branch)
+			a.NoError(err)
+			got, err := r.GetMetadata(tt.wantName)
+			a.NoError(err)
+			a.Equal(want, got.Source)
+			a.Equal(fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(sourceTestRepository))), got.Hash)
+			a.False(got.FetchedAt.IsZero())
+			a.Equal("publisher", got.SignedBy)
+			m, err := r.GetModule(tt.wantName, "c1", "")
+			a.NoError(err)
+			a.Equal("0.1.0", m.Version)
+		})
+	}
+}
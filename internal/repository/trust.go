@@ -0,0 +1,360 @@
+package repository
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"golang.org/x/crypto/ssh"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	signatureFileExtension = ".sig"
+	//sshSignatureNamespace is namespace repository file has to be signed for with 'ssh-keygen -Y sign -n'
+	sshSignatureNamespace = "epiphany-repository"
+	sshSignatureArmorType = "SSH SIGNATURE"
+	sshSignatureMagic     = "SSHSIG"
+)
+
+var (
+	//ErrUnsigned is returned by Install and Update if repository has no signature
+	ErrUnsigned = errors.New("is not signed")
+	//ErrUntrusted is returned by Install and Update if repository signature cannot be read or does not match any trusted
+	//key
+	ErrUntrusted = errors.New("does not match any trusted key")
+)
+
+//TrustedKey struct contains ed25519 public key of repository publisher
+type TrustedKey struct {
+	Name    string    `yaml:"name"`
+	Key     string    `yaml:"key"` // base64 encoded raw ed25519 public key
+	AddedAt time.Time `yaml:"addedAt"`
+}
+
+//Fingerprint returns SHA256 fingerprint of key in the same form as ssh-keygen does
+func (k TrustedKey) Fingerprint() string {
+	b, err := base64.StdEncoding.DecodeString(k.Key)
+	if err != nil {
+		return ""
+	}
+	pk, err := ssh.NewPublicKey(ed25519.PublicKey(b))
+	if err != nil {
+		return ""
+	}
+	return ssh.FingerprintSHA256(pk)
+}
+
+//trustedKeys is content of trust store file
+type trustedKeys struct {
+	Keys []TrustedKey `yaml:"keys"`
+}
+
+//TrustStore gives access to keys of repository publishers trusted by user. Keys are stored in single YAML file.
+type TrustStore struct {
+	file string
+	mu   sync.Mutex
+}
+
+//NewTrustStore returns TrustStore of keys stored in file. File is created when first key is added.
+func NewTrustStore(file string) *TrustStore {
+	return &TrustStore{file: file}
+}
+
+//List returns trusted keys ordered by name
+func (t *TrustStore) List() ([]TrustedKey, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Keys, nil
+}
+
+//Add parses public key (see ParsePublicKey) and stores it under name
+func (t *TrustStore) Add(name string, key []byte) (*TrustedKey, error) {
+	if !componentNameRegexp.MatchString(name) {
+		return nil, fmt.Errorf("key name %q can contain only letters, digits, '-' and '_'", name)
+	}
+	pk, err := ParsePublicKey(key)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys, err := t.load()
+	if err != nil {
+		return nil, err
+	}
+	encoded := base64.StdEncoding.EncodeToString(pk)
+	for _, k := range keys.Keys {
+		if k.Name == name {
+			return nil, fmt.Errorf("key %s is already trusted (remove it first to replace it)", name)
+		}
+		if k.Key == encoded {
+			return nil, fmt.Errorf("the same key is already trusted as %s", k.Name)
+		}
+	}
+	added := TrustedKey{Name: name, Key: encoded, AddedAt: time.Now().UTC().Truncate(time.Second)}
+	keys.Keys = append(keys.Keys, added)
+	err = t.save(keys)
+	if err != nil {
+		return nil, err
+	}
+	return &added, nil
+}
+
+//Remove deletes named key from trust store
+func (t *TrustStore) Remove(name string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys, err := t.load()
+	if err != nil {
+		return err
+	}
+	for i, k := range keys.Keys {
+		if k.Name == name {
+			keys.Keys = append(keys.Keys[:i], keys.Keys[i+1:]...)
+			return t.save(keys)
+		}
+	}
+	return fmt.Errorf("key %s is not trusted", name)
+}
+
+//verify checks signature of content with trusted keys and returns name of key content was signed with
+func (t *TrustStore) verify(content, signature []byte) (string, error) {
+	signedWith, err := parseSignature(content, signature)
+	if errors.Is(err, ErrUntrusted) {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("signature %w as it cannot be read: %v", ErrUntrusted, err)
+	}
+	keys, err := t.List()
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("signature %w as there are no trusted keys (add publisher key with 'e repos trust add')", ErrUntrusted)
+	}
+	for _, k := range keys {
+		pk, err := base64.StdEncoding.DecodeString(k.Key)
+		if err != nil || len(pk) != ed25519.PublicKeySize {
+			logger.Warn().Msgf("trusted key %s is not valid ed25519 key and is skipped", k.Name)
+			continue
+		}
+		if signedWith(pk) {
+			return k.Name, nil
+		}
+	}
+	return "", fmt.Errorf("signature %w", ErrUntrusted)
+}
+
+//load reads trust store file, missing file means no trusted keys
+func (t *TrustStore) load() (*trustedKeys, error) {
+	keys := &trustedKeys{}
+	b, err := ioutil.ReadFile(t.file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return keys, nil
+		}
+		return nil, err
+	}
+	err = yaml.Unmarshal(b, keys)
+	if err != nil {
+		return nil, fmt.Errorf("trusted keys file %s is not valid: %w", t.file, err)
+	}
+	sort.Slice(keys.Keys, func(i, j int) bool {
+		return keys.Keys[i].Name < keys.Keys[j].Name
+	})
+	return keys, nil
+}
+
+func (t *TrustStore) save(keys *trustedKeys) error {
+	b, err := yaml.Marshal(keys)
+	if err != nil {
+		return err
+	}
+	logger.Debug().Msgf("will write trusted keys to file %s", t.file)
+	return writeFile(t.file, b)
+}
+
+//ParsePublicKey reads ed25519 public key in one of forms:
+// PEM encoded PKIX key ("-----BEGIN PUBLIC KEY-----", as produced by 'openssl pkey -pubout')
+// OpenSSH authorized key line ("ssh-ed25519 AAAA... comment", as in files produced by 'ssh-keygen -t ed25519')
+// base64 encoded raw 32 bytes key
+func ParsePublicKey(b []byte) (ed25519.PublicKey, error) {
+	b = bytes.TrimSpace(b)
+	switch {
+	case bytes.HasPrefix(b, []byte("-----BEGIN")):
+		block, _ := pem.Decode(b)
+		if block == nil {
+			return nil, errors.New("incorrect PEM encoded public key")
+		}
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pk, ok := pub.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported public key type %T (only ed25519 keys are supported)", pub)
+		}
+		return pk, nil
+	case bytes.HasPrefix(b, []byte("ssh-")):
+		pub, _, _, _, err := ssh.ParseAuthorizedKey(b)
+		if err != nil {
+			return nil, err
+		}
+		if pub.Type() != ssh.KeyAlgoED25519 {
+			return nil, fmt.Errorf("unsupported public key type %s (only ed25519 keys are supported)", pub.Type())
+		}
+		pk, ok := pub.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey)
+		if !ok {
+			return nil, errors.New("incorrect ed25519 public key")
+		}
+		return pk, nil
+	default:
+		pk, err := base64.StdEncoding.DecodeString(string(b))
+		if err != nil {
+			return nil, fmt.Errorf("unrecognized public key format: %w", err)
+		}
+		if len(pk) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("incorrect ed25519 public key length %d", len(pk))
+		}
+		return pk, nil
+	}
+}
+
+//parseSignature reads signature of content in one of forms:
+// raw ed25519 signature, either binary (as produced by 'openssl pkeyutl -sign') or base64 encoded
+// armored SSH signature made for sshSignatureNamespace (as produced by 'ssh-keygen -Y sign')
+//Returned function checks if content was signed with provided public key.
+func parseSignature(content, b []byte) (func(ed25519.PublicKey) bool, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("-----BEGIN "+sshSignatureArmorType)) {
+		return parseSSHSignature(content, b)
+	}
+	sig := b
+	if len(b) != ed25519.SignatureSize {
+		var err error
+		sig, err = base64.StdEncoding.DecodeString(string(bytes.TrimSpace(b)))
+		if err != nil {
+			return nil, fmt.Errorf("incorrect signature: %w", err)
+		}
+		if len(sig) != ed25519.SignatureSize {
+			return nil, fmt.Errorf("incorrect ed25519 signature length %d", len(sig))
+		}
+	}
+	return func(pk ed25519.PublicKey) bool {
+		return ed25519.Verify(pk, content, sig)
+	}, nil
+}
+
+//sshSignature is blob of armored SSH signature following "SSHSIG" magic (see PROTOCOL.sshsig of OpenSSH)
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+//sshSignedData is what is actually signed in SSH signature following "SSHSIG" magic
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+//parseSSHSignature reads armored SSH signature and verifies it is correct signature of content. Returned function
+//checks if signature was made with provided public key.
+func parseSSHSignature(content, b []byte) (func(ed25519.PublicKey) bool, error) {
+	block, _ := pem.Decode(bytes.TrimSpace(b))
+	if block == nil || block.Type != sshSignatureArmorType || !bytes.HasPrefix(block.Bytes, []byte(sshSignatureMagic)) {
+		return nil, errors.New("incorrect SSH signature")
+	}
+	s := sshSignature{}
+	err := ssh.Unmarshal(block.Bytes[len(sshSignatureMagic):], &s)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect SSH signature: %w", err)
+	}
+	if s.Version != 1 {
+		return nil, fmt.Errorf("unsupported SSH signature version %d", s.Version)
+	}
+	if s.Namespace != sshSignatureNamespace {
+		return nil, fmt.Errorf("signature %w as it is made for namespace %q instead of %q", ErrUntrusted, s.Namespace, sshSignatureNamespace)
+	}
+	var hash []byte
+	switch s.HashAlgorithm {
+	case "sha256":
+		h := sha256.Sum256(content)
+		hash = h[:]
+	case "sha512":
+		h := sha512.Sum512(content)
+		hash = h[:]
+	default:
+		return nil, fmt.Errorf("unsupported SSH signature hash algorithm %s", s.HashAlgorithm)
+	}
+	pub, err := ssh.ParsePublicKey(s.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect SSH signature public key: %w", err)
+	}
+	if pub.Type() != ssh.KeyAlgoED25519 {
+		return nil, fmt.Errorf("unsupported SSH signature key type %s (only ed25519 keys are supported)", pub.Type())
+	}
+	sig := &ssh.Signature{}
+	err = ssh.Unmarshal(s.Signature, sig)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect SSH signature: %w", err)
+	}
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignedData{
+		Namespace:     s.Namespace,
+		Reserved:      s.Reserved,
+		HashAlgorithm: s.HashAlgorithm,
+		Hash:          hash,
+	})...)
+	if pub.Verify(signed, sig) != nil {
+		return func(ed25519.PublicKey) bool { return false }, nil
+	}
+	signedWith := pub.(ssh.CryptoPublicKey).CryptoPublicKey().(ed25519.PublicKey)
+	return func(pk ed25519.PublicKey) bool {
+		return bytes.Equal(pk, signedWith)
+	}, nil
+}
+
+//verify checks signature of fetched repository file. Unsigned or incorrectly signed repository is accepted with
+//warning only if allowUnsigned is set. Name of trusted key repository is signed with is returned.
+func (r *Repository) verify(f *fetched, allowUnsigned bool) (string, error) {
+	var err error
+	if f.signature == nil {
+		err = fmt.Errorf("repository %s %w (%s not found)", f.location, ErrUnsigned, f.location+signatureFileExtension)
+	} else {
+		var signedBy string
+		signedBy, err = r.trust.verify(f.content, f.signature)
+		if err == nil {
+			logger.Debug().Msgf("repository %s is signed with trusted key %s", f.location, signedBy)
+			return signedBy, nil
+		}
+		err = fmt.Errorf("repository %s %w", f.location, err)
+	}
+	if allowUnsigned {
+		logger.Warn().Msgf("%v, it is used anyway as '--allowUnsigned' is set", err)
+		return "", nil
+	}
+	return "", fmt.Errorf("%w (use '--allowUnsigned' if you trust it anyway)", err)
+}
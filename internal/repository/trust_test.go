@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestParsePublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pkix, err := x509.MarshalPKIXPublicKey(publicKey)
+	assert.NoError(t, err)
+	sshKey, err := ssh.NewPublicKey(publicKey)
+	assert.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+	rsaPkix, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		key     []byte
+		wantErr error
+	}{
+		{
+			name: "pem",
+			key:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}),
+		},
+		{
+			name: "ssh",
+			key:  []byte(strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshKey))) + " publisher@example.com\n"),
+		},
+		{
+			name: "base64",
+			key:  []byte(base64.StdEncoding.EncodeToString(publicKey) + "\n"),
+		},
+		{
+			name:    "rsa pem",
+			key:     pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: rsaPkix}),
+			wantErr: errors.New("unsupported public key type *rsa.PublicKey (only ed25519 keys are supported)"),
+		},
+		{
+			name:    "short base64",
+			key:     []byte(base64.StdEncoding.EncodeToString(publicKey[:16])),
+			wantErr: errors.New("incorrect ed25519 public key length 16"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := ParsePublicKey(tt.key)
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			a.NoError(err)
+			a.Equal(publicKey, got)
+		})
+	}
+}
+
+func TestTrustStore(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	key := func() []byte {
+		publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+		a.NoError(err)
+		return []byte(base64.StdEncoding.EncodeToString(publicKey))
+	}
+	k1, k2 := key(), key()
+	trust := NewTrustStore(util.UsedTrustedKeysFile)
+
+	keys, err := trust.List()
+	a.NoError(err)
+	a.Empty(keys)
+
+	added, err := trust.Add("team-b", k2)
+	a.NoError(err)
+	a.Equal(string(k2), added.Key)
+	a.Regexp("^SHA256:", added.Fingerprint())
+	_, err = trust.Add("team-a", k1)
+	a.NoError(err)
+	_, err = trust.Add("team-a", key())
+	a.EqualError(err, "key team-a is already trusted (remove it first to replace it)")
+	_, err = trust.Add("team-c", k1)
+	a.EqualError(err, "the same key is already trusted as team-a")
+	_, err = trust.Add("team c", key())
+	a.EqualError(err, `key name "team c" can contain only letters, digits, '-' and '_'`)
+
+	keys, err = NewTrustStore(util.UsedTrustedKeysFile).List()
+	a.NoError(err)
+	if a.Len(keys, 2) {
+		a.Equal("team-a", keys[0].Name)
+		a.Equal("team-b", keys[1].Name)
+	}
+
+	a.NoError(trust.Remove("team-a"))
+	a.EqualError(trust.Remove("team-a"), "key team-a is not trusted")
+	keys, err = trust.List()
+	a.NoError(err)
+	if a.Len(keys, 1) {
+		a.Equal("team-b", keys[0].Name)
+	}
+}
+
+func TestRepository_Install_Signature(t *testing.T) {
+	a := assert.New(t)
+	util.UsedConfigurationDirectory, util.UsedReposDirectory = setup(a)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	trustedPublic, trustedPrivate, err := ed25519.GenerateKey(rand.Reader)
+	a.NoError(err)
+	_, otherPrivate, err := ed25519.GenerateKey(rand.Reader)
+	a.NoError(err)
+	content := []byte("version: v1\nkind: Repository\nname: signed\ncomponents: []\n")
+	filePath := path.Join(util.UsedConfigurationDirectory, "r.yaml")
+	a.NoError(ioutil.WriteFile(filePath, content, 0644))
+	trust := NewTrustStore(util.UsedTrustedKeysFile)
+	r := New(util.UsedReposDirectory, trust)
+	name := inferRepoName(Source{Type: SourceFile, Origin: filePath})
+
+	tests := []struct {
+		name          string
+		signature     []byte
+		trusted       bool
+		allowUnsigned bool
+		wantErr       error
+		wantSignedBy  string
+	}{
+		{
+			name:      "no trusted keys",
+			signature: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(trustedPrivate, content))),
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:         "base64 signature",
+			signature:    []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(trustedPrivate, content)) + "\n"),
+			trusted:      true,
+			wantSignedBy: "publisher",
+		},
+		{
+			name:         "binary signature",
+			signature:    ed25519.Sign(trustedPrivate, content),
+			trusted:      true,
+			wantSignedBy: "publisher",
+		},
+		{
+			name:    "unsigned",
+			trusted: true,
+			wantErr: ErrUnsigned,
+		},
+		{
+			name:          "unsigned allowed",
+			trusted:       true,
+			allowUnsigned: true,
+		},
+		{
+			name:      "signed with other key",
+			signature: ed25519.Sign(otherPrivate, content),
+			trusted:   true,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:      "signature of other content",
+			signature: ed25519.Sign(trustedPrivate, append(content, '\n')),
+			trusted:   true,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:         "ssh signature",
+			signature:    sshSign(a, trustedPrivate, sshSignatureNamespace, "sha512", content),
+			trusted:      true,
+			wantSignedBy: "publisher",
+		},
+		{
+			name:         "ssh signature with sha256",
+			signature:    sshSign(a, trustedPrivate, sshSignatureNamespace, "sha256", content),
+			trusted:      true,
+			wantSignedBy: "publisher",
+		},
+		{
+			name:      "ssh signature with other key",
+			signature: sshSign(a, otherPrivate, sshSignatureNamespace, "sha512", content),
+			trusted:   true,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:      "ssh signature of other content",
+			signature: sshSign(a, trustedPrivate, sshSignatureNamespace, "sha512", append(content, '\n')),
+			trusted:   true,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:      "ssh signature for other namespace",
+			signature: sshSign(a, trustedPrivate, "file", "sha512", content),
+			trusted:   true,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:      "malformed signature",
+			signature: []byte("not a signature\n"),
+			trusted:   true,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:      "malformed ssh signature",
+			signature: []byte("-----BEGIN SSH SIGNATURE-----\nU1NIU0lH\n-----END SSH SIGNATURE-----\n"),
+			trusted:   true,
+			wantErr:   ErrUntrusted,
+		},
+		{
+			name:          "malformed signature allowed",
+			signature:     []byte("not a signature\n"),
+			trusted:       true,
+			allowUnsigned: true,
+		},
+		{
+			name:          "incorrect signature allowed",
+			signature:     ed25519.Sign(otherPrivate, content),
+			trusted:       true,
+			allowUnsigned: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			_ = os.Remove(util.UsedTrustedKeysFile)
+			if tt.trusted {
+				_, err := trust.Add("publisher", []byte(base64.StdEncoding.EncodeToString(trustedPublic)))
+				a.NoError(err)
+			}
+			_ = os.Remove(filePath + ".sig")
+			if tt.signature != nil {
+				a.NoError(ioutil.WriteFile(filePath+".sig", tt.signature, 0644))
+			}
+			_ = os.Remove(r.repoFilePath(name))
+
+			err := r.Install("file://"+filePath, true, "", tt.allowUnsigned)
+			if tt.wantErr != nil {
+				a.True(errors.Is(err, tt.wantErr), "unexpected error: %v", err)
+				a.NoFileExists(r.repoFilePath(name))
+				return
+			}
+			a.NoError(err)
+			m, err := r.GetMetadata(name)
+			a.NoError(err)
+			a.Equal(tt.wantSignedBy, m.SignedBy)
+		})
+	}
+}
+
+//sshSign creates armored signature of content the same way as 'ssh-keygen -Y sign' does
+func sshSign(a *assert.Assertions, key ed25519.PrivateKey, namespace, hashAlgorithm string, content []byte) []byte {
+	signer, err := ssh.NewSignerFromKey(key)
+	a.NoError(err)
+	var hash []byte
+	if hashAlgorithm == "sha256" {
+		h := sha256.Sum256(content)
+		hash = h[:]
+	} else {
+		h := sha512.Sum512(content)
+		hash = h[:]
+	}
+	signed := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignedData{
+		Namespace:     namespace,
+		HashAlgorithm: hashAlgorithm,
+		Hash:          hash,
+	})...)
+	sig, err := signer.Sign(rand.Reader, signed)
+	a.NoError(err)
+	blob := append([]byte(sshSignatureMagic), ssh.Marshal(sshSignature{
+		Version:       1,
+		PublicKey:     signer.PublicKey().Marshal(),
+		Namespace:     namespace,
+		HashAlgorithm: hashAlgorithm,
+		Signature:     ssh.Marshal(sig),
+	})...)
+	return pem.EncodeToMemory(&pem.Block{Type: sshSignatureArmorType, Bytes: blob})
+}
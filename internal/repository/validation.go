@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var (
+	componentNameRegexp = regexp.MustCompile("^[0-9a-zA-Z-_]+$")
+	errorLineRegexp     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	unknownFieldRegexp  = regexp.MustCompile(`^field \S+ not found in type `)
+)
+
+//ValidationError describes single problem found in repository file
+type ValidationError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", e.File, e.Message)
+}
+
+//ValidationErrors is list of all problems found in repository file
+type ValidationErrors []ValidationError
+
+func (es ValidationErrors) Error() string {
+	var lines []string
+	for _, e := range es {
+		lines = append(lines, e.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+//ValidateFile validates repository file. Unknown fields and component types other than provided componentTypes
+//are reported as problems too. If any problem is found returned error is ValidationErrors.
+func ValidateFile(filePath string, componentTypes []string) error {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	_, err = validate(filePath, content, validateOptions{strict: true, componentTypes: componentTypes})
+	return err
+}
+
+//validateOptions configures validate
+type validateOptions struct {
+	strict         bool     // report unknown fields as errors, otherwise they are only warnings
+	componentTypes []string // supported component types, type is not checked if empty
+}
+
+//validate checks repository file content against V1 schema and returns ValidationErrors with lines of problems.
+//Unknown fields are returned as warnings unless validation is strict, so that repository written for newer schema
+//can still be loaded.
+func validate(file string, content []byte, options validateOptions) (ValidationErrors, error) {
+	v := &validator{file: file, options: options}
+	root := &yamlv3.Node{}
+	err := yamlv3.Unmarshal(content, root)
+	if err != nil {
+		v.addDecodeError(err)
+		return v.warnings, v.result()
+	}
+	if len(root.Content) == 0 {
+		v.add(0, "file is empty")
+		return v.warnings, v.result()
+	}
+	// strict decoding reports unknown (most likely misspelled) fields and values of wrong types
+	decoder := yamlv3.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&V1{})
+	if err != nil {
+		v.addDecodeError(err)
+	}
+	v.validateRepository(root.Content[0])
+	return v.warnings, v.result()
+}
+
+//validator collects problems found in single repository file
+type validator struct {
+	file     string
+	options  validateOptions
+	errors   ValidationErrors
+	warnings ValidationErrors
+}
+
+func (v *validator) add(line int, format string, a ...interface{}) {
+	v.errors = append(v.errors, ValidationError{File: v.file, Line: line, Message: fmt.Sprintf(format, a...)})
+}
+
+//addDecodeError converts yaml decoding error messages like "line 3: ..." to ValidationError
+func (v *validator) addDecodeError(err error) {
+	var messages []string
+	var typeErr *yamlv3.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	} else {
+		messages = []string{err.Error()}
+	}
+	for _, m := range messages {
+		line := 0
+		if sm := errorLineRegexp.FindStringSubmatch(m); sm != nil {
+			line, _ = strconv.Atoi(sm[1])
+			m = sm[2]
+		}
+		if !v.options.strict && unknownFieldRegexp.MatchString(m) {
+			v.warnings = append(v.warnings, ValidationError{File: v.file, Line: line, Message: m})
+			continue
+		}
+		v.add(line, "%s", m)
+	}
+}
+
+func (v *validator) result() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.errors, func(i, j int) bool {
+		return v.errors[i].Line < v.errors[j].Line
+	})
+	return v.errors
+}
+
+//field returns value node of key in mapping node or nil if node is not mapping or key is missing
+func field(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+//requiredScalar returns value of scalar field and reports problem if it is missing or empty
+func (v *validator) requiredScalar(node *yamlv3.Node, key, owner string) string {
+	f := field(node, key)
+	if f == nil || f.Kind != yamlv3.ScalarNode || f.Value == "" {
+		v.add(node.Line, "%s is missing required field %s", owner, key)
+		return ""
+	}
+	return f.Value
+}
+
+//items returns content of sequence field or nil
+func items(node *yamlv3.Node, key string) []*yamlv3.Node {
+	f := field(node, key)
+	if f == nil || f.Kind != yamlv3.SequenceNode {
+		return nil
+	}
+	return f.Content
+}
+
+func (v *validator) validateRepository(node *yamlv3.Node) {
+	if node.Kind != yamlv3.MappingNode {
+		v.add(node.Line, "repository has to be a mapping")
+		return
+	}
+	if version := v.requiredScalar(node, "version", "repository"); version != "" && version != "v1" {
+		v.add(field(node, "version").Line, "unexpected repository version %s (expected v1)", version)
+	}
+	if kind := v.requiredScalar(node, "kind", "repository"); kind != "" && kind != "Repository" {
+		v.add(field(node, "kind").Line, "unexpected repository kind %s (expected Repository)", kind)
+	}
+	names := make(map[string]int)
+	for _, c := range items(node, "components") {
+		name := v.requiredScalar(c, "name", "component")
+		if name == "" {
+			continue
+		}
+		if !componentNameRegexp.MatchString(name) {
+			v.add(field(c, "name").Line, "component name %s contains characters other than letters, digits, '-' and '_'", name)
+		}
+		if line, ok := names[name]; ok {
+			v.add(c.Line, "component %s is already defined in line %d", name, line)
+			continue
+		}
+		names[name] = c.Line
+		v.validateComponent(c, name)
+	}
+}
+
+func (v *validator) validateComponent(node *yamlv3.Node, name string) {
+	componentType := v.requiredScalar(node, "type", "component "+name)
+	if componentType != "" && len(v.options.componentTypes) > 0 && !contains(v.options.componentTypes, componentType) {
+		v.add(field(node, "type").Line, "component %s has unsupported type %s (supported: %s)", name, componentType, strings.Join(v.options.componentTypes, ", "))
+	}
+	tags := make(map[string]int)
+	for _, t := range items(node, "tags") {
+		if t.Value == "" {
+			v.add(t.Line, "component %s has empty tag", name)
+			continue
+		}
+		if line, ok := tags[t.Value]; ok {
+			v.add(t.Line, "component %s has duplicated tag %s (already defined in line %d)", name, t.Value, line)
+			continue
+		}
+		tags[t.Value] = t.Line
+	}
+	versions := items(node, "versions")
+	if len(versions) == 0 {
+		v.add(node.Line, "component %s has no versions", name)
+	}
+	seen := make(map[string]int)
+	latestLine := 0
+	for _, cv := range versions {
+		version := v.requiredScalar(cv, "version", "version of component "+name)
+		if version == "" {
+			continue
+		}
+		owner := fmt.Sprintf("component %s version %s", name, version)
+		if line, ok := seen[version]; ok {
+			v.add(cv.Line, "%s is already defined in line %d", owner, line)
+		}
+		seen[version] = cv.Line
+		if latest := field(cv, "latest"); latest != nil && latest.Value == "true" {
+			if latestLine > 0 {
+				v.add(latest.Line, "%s is marked as latest but version in line %d is marked as latest too", owner, latestLine)
+			} else {
+				latestLine = latest.Line
+			}
+		}
+		v.validateVersion(cv, name, componentType, owner)
+	}
+}
+
+func (v *validator) validateVersion(node *yamlv3.Node, name, componentType, owner string) {
+	image := v.requiredScalar(node, "image", owner)
+	if image != "" && componentType == "docker" {
+		if _, err := reference.ParseNormalizedNamed(image); err != nil {
+			v.add(field(node, "image").Line, "%s has invalid image reference %s: %v", owner, image, err)
+		}
+	}
+	for _, key := range []string{"workdir", "shared"} {
+		if f := field(node, key); f != nil && f.Value != "" && !path.IsAbs(f.Value) {
+			v.add(f.Line, "%s has %s %s which is not absolute path", owner, key, f.Value)
+		}
+	}
+	mounts := make(map[string]bool)
+	for _, m := range items(node, "mounts") {
+		if !path.IsAbs(m.Value) {
+			v.add(m.Line, "%s has mount %s which is not absolute path", owner, m.Value)
+		}
+		if mounts[m.Value] {
+			v.add(m.Line, "%s has duplicated mount %s", owner, m.Value)
+		}
+		mounts[m.Value] = true
+	}
+	if f := field(node, "shared"); f != nil && f.Value != "" {
+		mounts[f.Value] = true
+	}
+	for _, s := range items(node, "secrets") {
+		inMount := false
+		for m := range mounts {
+			if s.Value == m || strings.HasPrefix(s.Value, strings.TrimSuffix(m, "/")+"/") {
+				inMount = true
+			}
+		}
+		if !inMount {
+			v.add(s.Line, "%s has secret %s which is not inside any of its mounts or shared directory", owner, s.Value)
+		}
+	}
+	v.uniqueNames(items(node, "commands"), owner, "command")
+	v.uniqueNames(items(node, "outputs"), owner, "output")
+	for _, r := range items(node, "requires") {
+		if r.Value == name {
+			v.add(r.Line, "%s requires itself", owner)
+		}
+	}
+}
+
+//uniqueNames checks that every item has name and names are unique
+func (v *validator) uniqueNames(nodes []*yamlv3.Node, owner, kind string) {
+	seen := make(map[string]int)
+	for _, n := range nodes {
+		name := v.requiredScalar(n, "name", fmt.Sprintf("%s of %s", kind, owner))
+		if name == "" {
+			continue
+		}
+		if line, ok := seen[name]; ok {
+			v.add(n.Line, "%s has duplicated %s %s (already defined in line %d)", owner, kind, name, line)
+			continue
+		}
+		seen[name] = n.Line
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
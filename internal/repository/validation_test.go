@@ -0,0 +1,217 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+	}{
+		{
+			name: "valid",
+			content: `version: v1
+kind: Repository
+name: r
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    latest: true
+    image: docker.io/hashicorp/terraform:0.12.28
+    workdir: /terraform
+    mounts:
+    - /terraform
+    shared: /shared
+    commands:
+    - name: init
+      command: init
+    outputs:
+    - name: ip
+    secrets:
+    - /terraform/*.tfstate*
+    - /shared/vms_rsa
+- name: c2
+  type: local
+  versions:
+  - version: 0.1.0
+    image: terraform
+    requires:
+    - c1
+`,
+		},
+		{
+			name:    "empty",
+			content: ``,
+			wantErr: errors.New("r.yaml: file is empty"),
+		},
+		{
+			name:    "not a yaml",
+			content: "version: v1\n\tkind: Repository",
+			wantErr: errors.New("r.yaml:2: found a tab character that violates indentation"),
+		},
+		{
+			name: "header",
+			content: `version: v2
+kind: Repo
+`,
+			wantErr: errors.New("r.yaml:1: unexpected repository version v2 (expected v1)\nr.yaml:2: unexpected repository kind Repo (expected Repository)"),
+		},
+		{
+			name: "unknown field",
+			content: `version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    imag: ubuntu
+`,
+			wantErr: errors.New("r.yaml:7: component c1 version 0.1.0 is missing required field image\nr.yaml:8: field imag not found in type repository.ComponentVersion"),
+		},
+		{
+			name: "components",
+			content: `version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    image: ubuntu
+- name: c1
+  type: docker
+  versions: []
+- name: c 2
+  type: unknown
+  versions: []
+`,
+			wantErr: errors.New("r.yaml:9: component c1 is already defined in line 4\nr.yaml:12: component name c 2 contains characters other than letters, digits, '-' and '_'\nr.yaml:12: component c 2 has no versions\nr.yaml:13: component c 2 has unsupported type unknown (supported: docker, local)"),
+		},
+		{
+			name: "tags",
+			content: `version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  description: component with tags
+  tags:
+  - azure
+  - ""
+  - azure
+  versions:
+  - version: 0.1.0
+    image: ubuntu
+`,
+			wantErr: errors.New("r.yaml:9: component c1 has empty tag\nr.yaml:10: component c1 has duplicated tag azure (already defined in line 8)"),
+		},
+		{
+			name: "versions",
+			content: `version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    latest: true
+    image: Not/Valid:Image
+    workdir: relative
+    mounts:
+    - /data
+    - /data
+    - data
+    commands:
+    - name: apply
+    - name: apply
+    outputs:
+    - description: no name
+    requires:
+    - c1
+  - version: 0.1.0
+    latest: true
+    image: ubuntu
+`,
+			wantErr: errors.New(`r.yaml:9: component c1 version 0.1.0 has invalid image reference Not/Valid:Image: invalid reference format: repository name must be lowercase
+r.yaml:10: component c1 version 0.1.0 has workdir relative which is not absolute path
+r.yaml:13: component c1 version 0.1.0 has duplicated mount /data
+r.yaml:14: component c1 version 0.1.0 has mount data which is not absolute path
+r.yaml:17: component c1 version 0.1.0 has duplicated command apply (already defined in line 16)
+r.yaml:19: output of component c1 version 0.1.0 is missing required field name
+r.yaml:21: component c1 version 0.1.0 requires itself
+r.yaml:22: component c1 version 0.1.0 is already defined in line 7
+r.yaml:23: component c1 version 0.1.0 is marked as latest but version in line 8 is marked as latest too`),
+		},
+		{
+			name: "secrets",
+			content: `version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    image: ubuntu
+    mounts:
+    - /terraform
+    secrets:
+    - /terraform/terraform.tfstate
+    - /terraform2/key
+    - relative/key
+`,
+			wantErr: errors.New(`r.yaml:13: component c1 version 0.1.0 has secret /terraform2/key which is not inside any of its mounts or shared directory
+r.yaml:14: component c1 version 0.1.0 has secret relative/key which is not inside any of its mounts or shared directory`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			_, err := validate("r.yaml", []byte(tt.content), validateOptions{strict: true, componentTypes: []string{"docker", "local"}})
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			a.NoError(err)
+		})
+	}
+}
+
+func TestValidateFile_Example(t *testing.T) {
+	assert.NoError(t, ValidateFile("../../docs/example-repository-v1.yaml", []string{"docker", "local"}))
+}
+
+func Test_validate_UnknownFieldsWhenLoading(t *testing.T) {
+	a := assert.New(t)
+	content := []byte(`version: v1
+kind: Repository
+name: r
+mirrors:
+- https://example.com
+components:
+- name: c1
+  type: wasm
+  versions:
+  - version: 0.1.0
+    image: docker.io/hashicorp/terraform:0.12.28
+    checksum: sha256:abc
+`)
+	warnings, err := validate("r.yaml", content, validateOptions{})
+	a.NoError(err)
+	a.EqualError(warnings, "r.yaml:4: field mirrors not found in type repository.V1\nr.yaml:12: field checksum not found in type repository.ComponentVersion")
+
+	_, err = validate("r.yaml", content, validateOptions{strict: true, componentTypes: []string{"docker", "local"}})
+	a.EqualError(err, "r.yaml:4: field mirrors not found in type repository.V1\nr.yaml:8: component c1 has unsupported type wasm (supported: docker, local)\nr.yaml:12: field checksum not found in type repository.ComponentVersion")
+
+	repo, err := parseV1Repository("r.yaml", content)
+	if a.NoError(err) {
+		a.Len(repo.Components, 1)
+	}
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const latestVersion = "latest"
+
+//selectVersion chooses version of component matching provided version expression. Exact version match has
+//precedence, then "latest" (or empty expression) selects version marked as latest or the highest released one
+//and any other expression is treated as semantic version range (e.g. "~0.1", "^1.2", ">= 0.1.0, <0.3" or "0.2.x")
+//from which the highest matching version is taken. Versions which are not semantic versions are only matched
+//exactly.
+func selectVersion(versions []ComponentVersion, expression string) (*ComponentVersion, error) {
+	expression = strings.TrimSpace(expression)
+	for i := range versions {
+		if expression != "" && versions[i].Version == expression {
+			return &versions[i], nil
+		}
+	}
+	if expression == "" || expression == latestVersion {
+		var latest []ComponentVersion
+		for _, v := range versions {
+			if v.IsLatest {
+				latest = append(latest, v)
+			}
+		}
+		if len(latest) == 1 {
+			return &latest[0], nil
+		}
+		if len(latest) > 1 {
+			versions = latest
+		}
+		expression = ">=0"
+	}
+	constraints, err := semver.NewConstraint(expression)
+	if err != nil {
+		return nil, fmt.Errorf("incorrect version constraint %s: %w", expression, err)
+	}
+	var result *ComponentVersion
+	var resultSemver *semver.Version
+	for i := range versions {
+		sv, err := semver.NewVersion(versions[i].Version)
+		if err != nil {
+			continue
+		}
+		if constraints.Check(sv) && (resultSemver == nil || sv.GreaterThan(resultSemver)) {
+			result = &versions[i]
+			resultSemver = sv
+		}
+	}
+	return result, nil
+}
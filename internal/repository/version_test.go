@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_selectVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		versions   []ComponentVersion
+		expression string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "exact non semantic version",
+			versions:   []ComponentVersion{{Version: "dev"}, {Version: "0.1.0", IsLatest: true}},
+			expression: "dev",
+			want:       "dev",
+		},
+		{
+			name:       "marked as latest",
+			versions:   []ComponentVersion{{Version: "0.1.0", IsLatest: true}, {Version: "0.2.0"}},
+			expression: "latest",
+			want:       "0.1.0",
+		},
+		{
+			name:       "empty expression means latest",
+			versions:   []ComponentVersion{{Version: "0.1.0", IsLatest: true}, {Version: "0.2.0"}},
+			expression: "",
+			want:       "0.1.0",
+		},
+		{
+			name:       "highest when none marked as latest",
+			versions:   []ComponentVersion{{Version: "0.10.0"}, {Version: "0.9.0"}, {Version: "1.0.0-rc.1"}, {Version: "dev"}},
+			expression: "latest",
+			want:       "0.10.0",
+		},
+		{
+			name:       "highest in range",
+			versions:   []ComponentVersion{{Version: "0.1.0"}, {Version: "0.1.3"}, {Version: "0.2.0", IsLatest: true}},
+			expression: "~0.1",
+			want:       "0.1.3",
+		},
+		{
+			name:       "nothing matches",
+			versions:   []ComponentVersion{{Version: "0.1.0"}},
+			expression: "^1",
+			want:       "",
+		},
+		{
+			name:       "tilde minor",
+			versions:   []ComponentVersion{{Version: "0.1.0"}, {Version: "0.1.9"}, {Version: "0.1.10-rc.1"}, {Version: "0.2.0"}},
+			expression: "~0.1",
+			want:       "0.1.9",
+		},
+		{
+			name:       "caret zero major",
+			versions:   []ComponentVersion{{Version: "0.2.1"}, {Version: "0.2.9"}, {Version: "0.3.0"}},
+			expression: "^0.2.1",
+			want:       "0.2.9",
+		},
+		{
+			name:       "wildcard",
+			versions:   []ComponentVersion{{Version: "1.0.0"}, {Version: "1.5.3"}, {Version: "2.0.0"}},
+			expression: "1.x",
+			want:       "1.5.3",
+		},
+		{
+			name:       "comparisons",
+			versions:   []ComponentVersion{{Version: "0.0.1"}, {Version: "0.2.9"}, {Version: "0.3.0"}},
+			expression: ">= 0.1.0, <0.3",
+			want:       "0.2.9",
+		},
+		{
+			name:       "prerelease allowed when requested",
+			versions:   []ComponentVersion{{Version: "1.0.0-beta"}, {Version: "1.0.0-rc.2"}},
+			expression: ">=1.0.0-rc.1",
+			want:       "1.0.0-rc.2",
+		},
+		{
+			name:       "incorrect expression",
+			versions:   []ComponentVersion{{Version: "0.1.0"}},
+			expression: "incorrect",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := selectVersion(tt.versions, tt.expression)
+			if tt.wantErr {
+				a.Error(err)
+				return
+			}
+			a.NoError(err)
+			if tt.want == "" {
+				a.Nil(got)
+			} else if a.NotNil(got) {
+				a.Equal(tt.want, got.Version)
+			}
+		})
+	}
+}
@@ -14,7 +14,13 @@ const (
 	DefaultEnvironmentConfigFileName    string = "config.yaml"
 	DefaultComponentRunsSubdirectory    string = "runs"
 	DefaultComponentMountsSubdirectory  string = "mounts"
+	DefaultSharedSubdirectory           string = "shared"
+	DefaultComponentOutputsFileName     string = "outputs.yaml"
 	DefaultRepoDirectoryName            string = "repos"
+	DefaultRepoMetadataFileExtension    string = ".metadata"
+	DefaultTrustedKeysFileName          string = "trusted-keys.yaml"
+	DefaultSnapshotsSubdirectory        string = "snapshots"
+	DefaultExportManifestFileName       string = "export.yaml"
 
 	GithubUrl                   = "https://raw.githubusercontent.com"
 	DefaultRepository           = "epiphany-platform/modules"
@@ -28,6 +34,8 @@ var (
 	UsedEnvironmentDirectory   string
 	UsedTempDirectory          string
 	UsedReposDirectory         string
+	UsedTrustedKeysFile        string
+	UsedSnapshotsDirectory     string
 )
 
 func init() {
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"path"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/epiphany-platform/cli/internal/util"
@@ -44,11 +43,7 @@ func (c *Config) CreateNewEnvironment(name string) (uuid.UUID, error) {
 		logger.Error().Err(err).Msg("creation of new environment failed")
 		return uuid.Nil, err
 	}
-	util.EnsureDirectory(path.Join(
-		util.UsedEnvironmentDirectory,
-		env.Uuid.String(),
-		"/shared", //TODO to consts
-	))
+	util.EnsureDirectory(env.SharedDirectory())
 	c.CurrentEnvironment = env.Uuid
 	logger.Debug().Msgf("will try to save updated config %+v", c)
 	return env.Uuid, c.Save()
@@ -166,13 +166,23 @@ current-environment: 00000000-0000-0000-0000-000000000000`),
 		{
 			name: "from not nil",
 			args: args{
-				name: "e1",
+				name: "e2",
 			},
 			mocked: []byte(`version: v1
 kind: Config
 current-environment: b3d7be89-461e-41eb-b130-0b4db1555d85`),
 			wantErr: nil,
 		},
+		{
+			name: "duplicated name",
+			args: args{
+				name: "e1",
+			},
+			mocked: []byte(`version: v1
+kind: Config
+current-environment: b3d7be89-461e-41eb-b130-0b4db1555d85`),
+			wantErr: errors.New("environment with name e1 already exists"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -186,11 +196,14 @@ current-environment: b3d7be89-461e-41eb-b130-0b4db1555d85`),
 			c, err := GetConfig()
 			a.NoErrorf(err, "error getting configuration %v", err)
 			u, err := c.CreateNewEnvironment(tt.args.name)
-			if tt.wantErr == nil {
-				a.NoError(err)
-			} else {
-				a.EqualError(err, tt.wantErr.Error())
+			if tt.wantErr != nil {
+				if a.Error(err) {
+					a.Contains(err.Error(), tt.wantErr.Error())
+				}
+				a.Equal(uuid.Nil, u)
+				return
 			}
+			a.NoError(err)
 			envDir := path.Join(util.UsedEnvironmentDirectory, u.String())
 			a.DirExists(envDir)
 			a.FileExists(path.Join(envDir, util.DefaultEnvironmentConfigFileName))
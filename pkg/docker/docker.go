@@ -3,20 +3,28 @@ package docker
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/moby/term"
+	godigest "github.com/opencontainers/go-digest"
 )
 
+//stopGracePeriod is time given to container to finish after job was cancelled before it gets killed
+const stopGracePeriod = 10 * time.Second
+
 func init() {
 	logger.Initialize()
 }
@@ -25,9 +33,10 @@ type Image struct {
 	Name string
 }
 
-func (image *Image) Pull() (string, error) { //TODO remove splitting log streams here, but use zerolog multiwriter
+//Pull downloads image. Download is aborted when ctx gets cancelled.
+func (image *Image) Pull(ctx context.Context) (string, error) { //TODO remove splitting log streams here, but use zerolog multiwriter
 	logger.Debug().Msg("will try to pull")
-	ctx, cli, err := clientAndContext()
+	cli, err := newClient()
 	if err != nil {
 		return "", err
 	}
@@ -79,8 +88,11 @@ func (image *Image) Pull() (string, error) { //TODO remove splitting log streams
 	return result, nil
 }
 
-func (image *Image) IsPulled() (bool, error) {
-	ctx, cli, err := clientAndContext()
+//IsPulled checks if image is present in docker daemon. Image name is compared with repository tags (and with
+//repository digests if name contains digest) after normalization, so "terraform" does not match
+//"org/terraform".
+func (image *Image) IsPulled(ctx context.Context) (bool, error) {
+	cli, err := newClient()
 	if err != nil {
 		return false, err
 	}
@@ -89,9 +101,9 @@ func (image *Image) IsPulled() (bool, error) {
 		return false, err
 	}
 	for _, s := range summaries {
-		for _, rt := range s.RepoTags {
+		for _, rt := range append(s.RepoTags, s.RepoDigests...) {
 			logger.Debug().Msgf("repo tag: %s", rt)
-			if strings.HasSuffix(image.Name, rt) {
+			if sameReference(image.Name, rt) {
 				return true, nil
 			}
 		}
@@ -99,6 +111,94 @@ func (image *Image) IsPulled() (bool, error) {
 	return false, nil
 }
 
+//LocalDigest returns content digest (e.g. "sha256:...") of image present in docker daemon. Image tag is resolved
+//by daemon, so digest of image currently tagged with it is returned. Images which were never pushed to or pulled
+//from registry have no digest and error is returned.
+func (image *Image) LocalDigest(ctx context.Context) (string, error) {
+	cli, err := newClient()
+	if err != nil {
+		return "", err
+	}
+	inspect, _, err := cli.ImageInspectWithRaw(ctx, image.Name)
+	if err != nil {
+		return "", err
+	}
+	return repoDigest(image.Name, inspect.RepoDigests)
+}
+
+//RemoteDigest returns content digest of image currently available in registry. Registry is queried by docker
+//daemon, so credentials are not needed for public images only.
+func (image *Image) RemoteDigest(ctx context.Context) (string, error) {
+	cli, err := newClient()
+	if err != nil {
+		return "", err
+	}
+	inspect, err := cli.DistributionInspect(ctx, image.Name, "")
+	if err != nil {
+		return "", err
+	}
+	return inspect.Descriptor.Digest.String(), nil
+}
+
+//PinnedName returns reference to image name with provided digest, e.g. "docker.io/org/image@sha256:...". Tag of
+//name is dropped.
+func PinnedName(name, digest string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return "", err
+	}
+	d, err := godigest.Parse(digest)
+	if err != nil {
+		return "", err
+	}
+	pinned, err := reference.WithDigest(reference.TrimNamed(named), d)
+	if err != nil {
+		return "", err
+	}
+	return pinned.String(), nil
+}
+
+//sameReference checks if image references point to the same repository and tag (or digest) after normalization.
+//Missing tag means "latest".
+func sameReference(a, b string) bool {
+	na, err := normalize(a)
+	if err != nil {
+		return false
+	}
+	nb, err := normalize(b)
+	if err != nil {
+		return false
+	}
+	return na == nb
+}
+
+func normalize(name string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return "", err
+	}
+	return reference.TagNameOnly(named).String(), nil
+}
+
+//repoDigest returns digest from repository digests (in "repository@digest" form) of image repository
+func repoDigest(name string, repoDigests []string) (string, error) {
+	named, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		return "", err
+	}
+	for _, rd := range repoDigests {
+		r, err := reference.ParseNormalizedNamed(rd)
+		if err != nil {
+			continue
+		}
+		canonical, ok := r.(reference.Canonical)
+		if ok && r.Name() == named.Name() {
+			return canonical.Digest().String(), nil
+		}
+	}
+	return "", fmt.Errorf("image %s has no digest of repository %s (it was not pulled from registry)", name, named.Name())
+}
+
 type Job struct {
 	Image                string
 	Command              string
@@ -106,14 +206,30 @@ type Job struct {
 	WorkDirectory        string
 	Mounts               map[string]string
 	EnvironmentVariables map[string]string
+	Stdout               io.Writer // os.Stdout if nil
+	Stderr               io.Writer // os.Stderr if nil
+	Entrypoint           string    // overrides image entrypoint if not empty
+	Interactive          bool      // keeps stdin open and forwards os.Stdin to container
+	Tty                  bool      // allocates pseudo-TTY for container
+}
+
+//ExitError is returned when container of Job finished with non-zero exit code
+type ExitError struct {
+	Code int64
 }
 
-func (job Job) Run() error {
-	return run(job)
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("container exited with code %d", e.Code)
 }
 
-func run(job Job) error {
-	ctx, cli, err := clientAndContext()
+//Run executes job in new container and waits for it to finish. If container exits with non-zero code
+//returned error is *ExitError. If ctx gets cancelled container is stopped and ctx error is returned.
+func (job Job) Run(ctx context.Context) error {
+	return run(ctx, job)
+}
+
+func run(ctx context.Context, job Job) error {
+	cli, err := newClient()
 	if err != nil {
 		return err
 	}
@@ -121,7 +237,15 @@ func run(job Job) error {
 	for k, v := range job.EnvironmentVariables {
 		envs = append(envs, fmt.Sprintf("%s=%s", k, v))
 	}
-	commandAndArgs := append([]string{job.Command}, job.Args...)
+	var commandAndArgs []string
+	if job.Command != "" {
+		commandAndArgs = append(commandAndArgs, job.Command)
+	}
+	commandAndArgs = append(commandAndArgs, job.Args...)
+	var entrypoint []string
+	if job.Entrypoint != "" {
+		entrypoint = []string{job.Entrypoint}
+	}
 	var mounts []mount.Mount
 	for k, v := range job.Mounts {
 		mounts = append(
@@ -137,11 +261,17 @@ func run(job Job) error {
 	resp, err := cli.ContainerCreate(
 		ctx,
 		&container.Config{
-			Image:      job.Image,
-			Cmd:        commandAndArgs,
-			WorkingDir: job.WorkDirectory,
-			Env:        envs,
-			Tty:        false,
+			Image:        job.Image,
+			Entrypoint:   entrypoint,
+			Cmd:          commandAndArgs,
+			WorkingDir:   job.WorkDirectory,
+			Env:          envs,
+			Tty:          job.Tty,
+			OpenStdin:    job.Interactive,
+			StdinOnce:    job.Interactive,
+			AttachStdin:  job.Interactive,
+			AttachStdout: true,
+			AttachStderr: true,
 		}, &container.HostConfig{
 			Mounts: mounts,
 		},
@@ -152,35 +282,144 @@ func run(job Job) error {
 	if err != nil {
 		return err
 	}
-	defer removeFinishedContainer(cli, ctx, resp.ID)
+	defer removeFinishedContainer(ctx, cli, resp.ID)
+
+	statusCh, errCh := cli.ContainerWait(ctx, resp.ID, container.WaitConditionNextExit)
+
+	stdout, stderr := job.Stdout, job.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
 
-	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+	if job.Interactive || job.Tty {
+		err = runAttached(ctx, cli, resp.ID, job, stdout, stderr)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+			return err
+		}
+		out, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+		if err != nil {
+			return err
+		}
+		_, _ = stdcopy.StdCopy(stdout, stderr, out)
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-errCh:
 		return err
+	case status := <-statusCh:
+		if status.Error != nil {
+			return errors.New(status.Error.Message)
+		}
+		logger.Debug().Msgf("container %s exited with status code %d", resp.ID, status.StatusCode)
+		if status.StatusCode != 0 {
+			return &ExitError{Code: status.StatusCode}
+		}
+		return nil
 	}
-	out, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+}
+
+//runAttached attaches to container streams, starts container and copies its output until container closes it.
+//In Tty mode local terminal is switched to raw mode and container pseudo-TTY follows local terminal size.
+func runAttached(ctx context.Context, cli *client.Client, containerID string, job Job, stdout, stderr io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	hijacked, err := cli.ContainerAttach(ctx, containerID, types.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  job.Interactive,
+		Stdout: true,
+		Stderr: true,
+	})
 	if err != nil {
 		return err
 	}
+	defer hijacked.Close()
+	go func() {
+		// hijacked connection does not follow context so it has to be closed to stop copying output
+		<-ctx.Done()
+		hijacked.Close()
+	}()
 
-	_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, out) //TODO write logs to file as well
+	inFd, isTerminal := term.GetFdInfo(os.Stdin)
+	if job.Tty && isTerminal {
+		state, err := term.SetRawTerminal(inFd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = term.RestoreTerminal(inFd, state)
+		}()
+	}
+
+	if err := cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		return err
+	}
+
+	if job.Tty && isTerminal {
+		monitorTtySize(ctx, cli, containerID, inFd)
+	}
 
+	if job.Interactive {
+		go func() {
+			_, _ = io.Copy(hijacked.Conn, os.Stdin)
+			_ = hijacked.CloseWrite()
+		}()
+	}
+
+	if job.Tty {
+		_, err = io.Copy(stdout, hijacked.Reader)
+	} else {
+		_, err = stdcopy.StdCopy(stdout, stderr, hijacked.Reader)
+	}
+	if err != nil && err != io.EOF {
+		logger.Debug().Err(err).Msg("copying attached container output finished with error")
+	}
 	return nil
 }
 
-func clientAndContext() (context.Context, *client.Client, error) {
-	ctx := context.Background()
-	cli, err := client.NewClientWithOpts(client.FromEnv)
+//resizeTty sets size of container pseudo-TTY to current size of local terminal
+func resizeTty(ctx context.Context, cli *client.Client, containerID string, fd uintptr) {
+	ws, err := term.GetWinsize(fd)
+	if err != nil {
+		logger.Debug().Err(err).Msg("cannot get terminal size")
+		return
+	}
+	err = cli.ContainerResize(ctx, containerID, types.ResizeOptions{Height: uint(ws.Height), Width: uint(ws.Width)})
 	if err != nil {
-		return nil, nil, err
+		logger.Debug().Err(err).Msg("cannot resize container tty")
 	}
-	return ctx, cli, nil
 }
 
-func removeFinishedContainer(cli *client.Client, ctx context.Context, containerID string) {
+func newClient() (*client.Client, error) {
+	return client.NewClientWithOpts(client.FromEnv)
+}
+
+//removeFinishedContainer removes container after job finished. If job context was cancelled container is stopped
+//first with stopGracePeriod. Background context is used as job context might be already done.
+func removeFinishedContainer(ctx context.Context, cli *client.Client, containerID string) {
 	//TODO probably add check if container is running with retry because of:
 	//Error response from daemon: You cannot remove a running container XXX. Stop the container before attempting removal or force remove
 
-	err := cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{})
+	if ctx.Err() != nil {
+		logger.Warn().Msgf("job cancelled, stopping container %s", containerID)
+		timeout := stopGracePeriod
+		err := cli.ContainerStop(context.Background(), containerID, &timeout)
+		if err != nil {
+			logger.Warn().Err(err).Msg("cannot stop container of cancelled job")
+		}
+	}
+	err := cli.ContainerRemove(context.Background(), containerID, types.ContainerRemoveOptions{})
 	if err != nil {
 		logger.Warn().Err(err).Msg("cannot remove container after it finished it's job")
 	}
@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDigest  = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	otherDigest = "sha256:fedcba9876543210fedcba9876543210fedcba9876543210fedcba9876543210"
+)
+
+func Test_sameReference(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want bool
+	}{
+		{
+			name: "same",
+			a:    "epiphanyplatform/azbi:0.1.0",
+			b:    "epiphanyplatform/azbi:0.1.0",
+			want: true,
+		},
+		{
+			name: "normalized registry and latest tag",
+			a:    "ubuntu",
+			b:    "docker.io/library/ubuntu:latest",
+			want: true,
+		},
+		{
+			name: "other repository with the same suffix",
+			a:    "org/terraform:0.12.28",
+			b:    "terraform:0.12.28",
+			want: false,
+		},
+		{
+			name: "other tag",
+			a:    "hashicorp/terraform:0.12.28",
+			b:    "hashicorp/terraform:0.12.29",
+			want: false,
+		},
+		{
+			name: "digest",
+			a:    "docker.io/hashicorp/terraform@" + testDigest,
+			b:    "hashicorp/terraform@" + testDigest,
+			want: true,
+		},
+		{
+			name: "invalid",
+			a:    "Not/Valid",
+			b:    "Not/Valid",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			a.Equal(tt.want, sameReference(tt.a, tt.b))
+		})
+	}
+}
+
+func Test_repoDigest(t *testing.T) {
+	tests := []struct {
+		name        string
+		image       string
+		repoDigests []string
+		want        string
+		wantErr     error
+	}{
+		{
+			name:        "matching repository",
+			image:       "hashicorp/terraform:0.12.28",
+			repoDigests: []string{"mirror.example.com/terraform@" + otherDigest, "hashicorp/terraform@" + testDigest},
+			want:        testDigest,
+		},
+		{
+			name:        "no repository digest",
+			image:       "terraform",
+			repoDigests: []string{"hashicorp/terraform@" + testDigest},
+			wantErr:     errors.New("image terraform has no digest of repository docker.io/library/terraform (it was not pulled from registry)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := repoDigest(tt.image, tt.repoDigests)
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			a.NoError(err)
+			a.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestPinnedName(t *testing.T) {
+	a := assert.New(t)
+	got, err := PinnedName("hashicorp/terraform:0.12.28", testDigest)
+	a.NoError(err)
+	a.Equal("docker.io/hashicorp/terraform@"+testDigest, got)
+
+	_, err = PinnedName("hashicorp/terraform:0.12.28", "0.12.28")
+	a.Error(err)
+}
@@ -0,0 +1,30 @@
+// +build !windows
+
+package docker
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/docker/docker/client"
+)
+
+//monitorTtySize resizes container pseudo-TTY now and every time local terminal receives SIGWINCH
+func monitorTtySize(ctx context.Context, cli *client.Client, containerID string, fd uintptr) {
+	resizeTty(ctx, cli, containerID, fd)
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGWINCH)
+	go func() {
+		defer signal.Stop(sigCh)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-sigCh:
+				resizeTty(ctx, cli, containerID, fd)
+			}
+		}
+	}()
+}
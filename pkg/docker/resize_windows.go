@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"context"
+	"time"
+
+	"github.com/docker/docker/client"
+	"github.com/moby/term"
+)
+
+//monitorTtySize resizes container pseudo-TTY now and polls local terminal size as there is no SIGWINCH on windows
+func monitorTtySize(ctx context.Context, cli *client.Client, containerID string, fd uintptr) {
+	resizeTty(ctx, cli, containerID, fd)
+	go func() {
+		var height, width uint16
+		ticker := time.NewTicker(250 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ws, err := term.GetWinsize(fd)
+				if err != nil {
+					return
+				}
+				if ws.Height != height || ws.Width != width {
+					height, width = ws.Height, ws.Width
+					resizeTty(ctx, cli, containerID, fd)
+				}
+			}
+		}
+	}()
+}
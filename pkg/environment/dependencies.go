@@ -0,0 +1,122 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+)
+
+//checkRequirements verifies that all components required by cv are installed in environment
+func (e *Environment) checkRequirements(cv InstalledComponentVersion) error {
+	for _, r := range cv.Requires {
+		if r == cv.Name {
+			return fmt.Errorf("component %s cannot require itself", cv.Name)
+		}
+		if _, err := e.GetComponentByName(r); err != nil {
+			return fmt.Errorf("component %s requires component %s which is not installed", cv.Name, r)
+		}
+	}
+	return nil
+}
+
+//requiredBy returns names of installed components requiring named component
+func (e *Environment) requiredBy(name string) []string {
+	var result []string
+	for _, ic := range e.Installed {
+		for _, r := range ic.Requires {
+			if r == name {
+				result = append(result, ic.Name)
+			}
+		}
+	}
+	return result
+}
+
+//InstallOrder returns installed components sorted so that every component comes after components it requires.
+//Components without dependencies between them keep installation order.
+func (e *Environment) InstallOrder() ([]InstalledComponentVersion, error) {
+	indexesByName := make(map[string][]int)
+	for i, ic := range e.Installed {
+		indexesByName[ic.Name] = append(indexesByName[ic.Name], i)
+	}
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(e.Installed))
+	var result []InstalledComponentVersion
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		ic := e.Installed[i]
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle detected: %s", strings.Join(append(path, ic.Name), " -> "))
+		}
+		state[i] = visiting
+		// path is copied, so that recursive calls for sibling requirements do not share its backing array
+		next := append(append([]string(nil), path...), ic.Name)
+		for _, r := range ic.Requires {
+			indexes, ok := indexesByName[r]
+			if !ok {
+				return fmt.Errorf("component %s requires component %s which is not installed", ic.Name, r)
+			}
+			for _, j := range indexes {
+				if err := visit(j, next); err != nil {
+					return err
+				}
+			}
+		}
+		state[i] = visited
+		result = append(result, ic)
+		return nil
+	}
+	for i := range e.Installed {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+//RunAll runs named command of all installed components in InstallOrder or in reversed order if reverse is true.
+//Components not providing command are skipped. Outputs are collected after each successful run so that next
+//components can use them. Running stops on first failure.
+func (e *Environment) RunAll(ctx context.Context, command string, reverse bool, newProcessor func(cv *InstalledComponentVersion) func(string) (string, error), options RunOptions) error {
+	ordered, err := e.InstallOrder()
+	if err != nil {
+		return err
+	}
+	if reverse {
+		for i, j := 0, len(ordered)-1; i < j; i, j = i+1, j-1 {
+			ordered[i], ordered[j] = ordered[j], ordered[i]
+		}
+	}
+	ran := 0
+	for i := range ordered {
+		cv := &ordered[i]
+		if !cv.HasCommand(command) {
+			logger.Info().Msgf("component %s has no command %s, skipping", cv.Name, command)
+			continue
+		}
+		logger.Info().Msgf("running %s %s", cv.Name, command)
+		err = cv.Run(ctx, command, newProcessor(cv), options)
+		if err != nil {
+			return fmt.Errorf("running %s %s failed: %w", cv.Name, command, err)
+		}
+		err = e.CollectOutputs(cv)
+		if err != nil {
+			return err
+		}
+		ran++
+	}
+	if ran == 0 {
+		return errors.New("no installed component provides command " + command)
+	}
+	return nil
+}
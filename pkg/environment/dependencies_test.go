@@ -0,0 +1,176 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironment_InstallOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []InstalledComponentVersion
+		want      []string
+		wantErr   error
+	}{
+		{
+			name: "no dependencies keeps installation order",
+			installed: []InstalledComponentVersion{
+				{Name: "c1"},
+				{Name: "c2"},
+			},
+			want: []string{"c1", "c2"},
+		},
+		{
+			name: "dependencies first",
+			installed: []InstalledComponentVersion{
+				{Name: "app", Requires: []string{"k8s", "db"}},
+				{Name: "db", Requires: []string{"infra"}},
+				{Name: "k8s", Requires: []string{"infra"}},
+				{Name: "infra"},
+			},
+			want: []string{"infra", "k8s", "db", "app"},
+		},
+		{
+			name: "cycle",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Requires: []string{"c2"}},
+				{Name: "c2", Requires: []string{"c1"}},
+			},
+			wantErr: errors.New("dependency cycle detected: c1 -> c2 -> c1"),
+		},
+		{
+			name: "cycle behind sibling requirements",
+			installed: []InstalledComponentVersion{
+				{Name: "app", Requires: []string{"infra"}},
+				{Name: "infra", Requires: []string{"net", "k8s"}},
+				{Name: "net", Requires: []string{"dns", "vpn"}},
+				{Name: "dns"},
+				{Name: "vpn"},
+				{Name: "k8s", Requires: []string{"db"}},
+				{Name: "db", Requires: []string{"infra"}},
+			},
+			wantErr: errors.New("dependency cycle detected: app -> infra -> k8s -> db -> infra"),
+		},
+		{
+			name: "missing dependency",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Requires: []string{"c2"}},
+			},
+			wantErr: errors.New("component c1 requires component c2 which is not installed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			e := &Environment{Installed: tt.installed}
+			got, err := e.InstallOrder()
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			a.NoError(err)
+			var names []string
+			for _, ic := range got {
+				names = append(names, ic.Name)
+			}
+			a.Equal(tt.want, names)
+		})
+	}
+}
+
+func TestEnvironment_Requirements(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "requirements")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+	e, err := Create("requirements")
+	if err != nil {
+		t.Fatal(err)
+	}
+	infra := InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "infra", Type: "local", Version: "v1", Image: "sh"}
+	app := InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "app", Type: "local", Version: "v1", Image: "sh", Requires: []string{"infra"}}
+
+	a.EqualError(e.Install(context.Background(), app), "component app requires component infra which is not installed")
+	a.NoError(e.Install(context.Background(), infra))
+	a.NoError(e.Install(context.Background(), app))
+	a.EqualError(e.Uninstall("infra", ""), "component infra is required by app")
+	a.NoError(e.Uninstall("app", ""))
+	a.NoError(e.Uninstall("infra", ""))
+}
+
+func TestEnvironment_RunAll(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "run-all")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+
+	tests := []struct {
+		name    string
+		command string
+		reverse bool
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "dependency order",
+			command: "apply",
+			want:    "infra\napp\n",
+		},
+		{
+			name:    "reverse order",
+			command: "destroy",
+			reverse: true,
+			want:    "app\ninfra\n",
+		},
+		{
+			name:    "stops on first failure",
+			command: "fail",
+			want:    "infra\n",
+			wantErr: errors.New("running infra fail failed: command exited with code 1"),
+		},
+		{
+			name:    "no component provides command",
+			command: "unknown",
+			wantErr: errors.New("no installed component provides command unknown"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			e, err := Create(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			log := path.Join(e.SharedDirectory(), "log")
+			commands := []InstalledComponentCommand{
+				{Name: "apply", Command: "-c", Args: []string{`basename "$(pwd)" >> "$1"`, "sh", "/shared/log"}},
+				{Name: "destroy", Command: "-c", Args: []string{`basename "$(pwd)" >> "$1"`, "sh", "/shared/log"}},
+				{Name: "fail", Command: "-c", Args: []string{`basename "$(pwd)" >> "$1"; exit 1`, "sh", "/shared/log"}},
+			}
+			e.Installed = []InstalledComponentVersion{
+				{EnvironmentRef: e.Uuid, Name: "app", Type: "local", Version: "v1", Image: "sh", WorkDirectory: "/app", Mounts: []string{"/app"}, Shared: "/shared", Commands: commands, Requires: []string{"infra"}},
+				{EnvironmentRef: e.Uuid, Name: "infra", Type: "local", Version: "v1", Image: "sh", WorkDirectory: "/infra", Mounts: []string{"/infra"}, Shared: "/shared", Commands: commands},
+			}
+
+			err = e.RunAll(context.Background(), tt.command, tt.reverse, func(*InstalledComponentVersion) func(string) (string, error) {
+				return func(s string) (string, error) { return s, nil }
+			}, RunOptions{})
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+			} else {
+				a.NoError(err)
+			}
+			content, _ := ioutil.ReadFile(log)
+			a.Equal(tt.want, string(content))
+		})
+	}
+}
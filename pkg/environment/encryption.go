@@ -0,0 +1,220 @@
+package environment
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"filippo.io/age"
+	"filippo.io/age/agessh"
+	"golang.org/x/crypto/ssh"
+)
+
+//Encrypted archive is age file (https://age-encryption.org/v1) encrypted either with passphrase or for SSH public
+//keys (ssh-ed25519 and ssh-rsa) of recipients
+const (
+	encryptedArchiveMagic     = "age-encryption.org/v1"
+	encryptedArchiveExtension = ".enc"
+	scryptStanzaType          = "scrypt"
+)
+
+//scryptLogN is work factor used to derive key from passphrase
+var scryptLogN = 18
+
+//ErrEncrypted is returned by Import if archive is encrypted and no passphrase or identity was provided
+var ErrEncrypted = errors.New("is encrypted")
+
+//isEncryptedArchive checks if file starts with age header line
+func isEncryptedArchive(file string) (bool, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	b := make([]byte, len(encryptedArchiveMagic)+1)
+	_, err = io.ReadFull(f, b)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return string(b) == encryptedArchiveMagic+"\n", nil
+}
+
+//stanzaTypes is age identity which never unwraps file key, it only records types of recipient stanzas in header
+type stanzaTypes []string
+
+func (t *stanzaTypes) Unwrap(stanzas []*age.Stanza) ([]byte, error) {
+	for _, s := range stanzas {
+		*t = append(*t, s.Type)
+	}
+	return nil, age.ErrIncorrectIdentity
+}
+
+//NeedsPassphrase checks if file is encrypted archive which can be decrypted with passphrase
+func NeedsPassphrase(file string) (bool, error) {
+	encrypted, err := isEncryptedArchive(file)
+	if err != nil || !encrypted {
+		return false, err
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	types := &stanzaTypes{}
+	_, err = age.Decrypt(f, types)
+	var noMatch *age.NoIdentityMatchError
+	if !errors.As(err, &noMatch) {
+		return false, fmt.Errorf("incorrect encrypted archive: %v", err)
+	}
+	for _, t := range *types {
+		if t == scryptStanzaType {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+//encryptFile encrypts src file into dst file either for passphrase (if not empty) or for all recipients
+func encryptFile(src, dst, passphrase string, recipients [][]byte) error {
+	if passphrase == "" && len(recipients) == 0 {
+		return errors.New("passphrase or at least one recipient is required to encrypt archive")
+	}
+	if passphrase != "" && len(recipients) > 0 {
+		return errors.New("archive can be encrypted either with passphrase or for recipients, not both")
+	}
+	var ageRecipients []age.Recipient
+	if passphrase != "" {
+		r, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return err
+		}
+		r.SetWorkFactor(scryptLogN)
+		ageRecipients = append(ageRecipients, r)
+	}
+	for _, b := range recipients {
+		r, err := agessh.ParseRecipient(string(bytes.TrimSpace(b)))
+		if err != nil {
+			return fmt.Errorf("incorrect recipient: %w", err)
+		}
+		ageRecipients = append(ageRecipients, r)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	err = encrypt(out, in, ageRecipients)
+	if err2 := out.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+func encrypt(w io.Writer, r io.Reader, recipients []age.Recipient) error {
+	ew, err := age.Encrypt(w, recipients...)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(ew, r)
+	if err2 := ew.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+//decryptFile decrypts src file into dst file with passphrase or one of identities
+func decryptFile(src, dst, passphrase string, identities [][]byte) error {
+	if passphrase == "" && len(identities) == 0 {
+		return ErrEncrypted
+	}
+	var ageIdentities []age.Identity
+	if passphrase != "" {
+		i, err := age.NewScryptIdentity(passphrase)
+		if err != nil {
+			return err
+		}
+		ageIdentities = append(ageIdentities, i)
+	}
+	for _, b := range identities {
+		i, err := parseIdentity(b)
+		if err != nil {
+			return err
+		}
+		ageIdentities = append(ageIdentities, i)
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+	r, err := age.Decrypt(in, ageIdentities...)
+	var noMatch *age.NoIdentityMatchError
+	if errors.As(err, &noMatch) {
+		return errors.New("archive cannot be decrypted with provided passphrase or identities")
+	}
+	if err != nil {
+		return fmt.Errorf("unable to decrypt archive: %w", err)
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, r)
+	if err != nil {
+		err = fmt.Errorf("encrypted archive is corrupted or truncated: %w", err)
+	}
+	if err2 := out.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+	return err
+}
+
+//parseIdentity reads unencrypted SSH private key (OpenSSH or PEM format) used to decrypt archive
+func parseIdentity(b []byte) (age.Identity, error) {
+	k, err := ssh.ParseRawPrivateKey(b)
+	if err != nil {
+		var missing *ssh.PassphraseMissingError
+		if errors.As(err, &missing) {
+			return nil, errors.New("identity protected with passphrase is not supported")
+		}
+		return nil, fmt.Errorf("incorrect identity: %w", err)
+	}
+	switch pk := k.(type) {
+	case *ed25519.PrivateKey:
+		return agessh.NewEd25519Identity(*pk)
+	case ed25519.PrivateKey:
+		return agessh.NewEd25519Identity(pk)
+	case *rsa.PrivateKey:
+		return agessh.NewRSAIdentity(pk)
+	default:
+		return nil, fmt.Errorf("unsupported identity key type %T (only ed25519 and rsa keys are supported)", k)
+	}
+}
@@ -0,0 +1,304 @@
+package environment
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/mholt/archiver/v3"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+//testKeys returns authorized_keys line and PEM encoded private key of generated ed25519 and rsa keys
+func testKeys(t *testing.T) (ed25519Pub, ed25519Priv, rsaPub, rsaPriv []byte) {
+	edPub, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshEdPub, err := ssh.NewPublicKey(edPub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(edPriv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sshRsaPub, err := ssh.NewPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ssh.MarshalAuthorizedKey(sshEdPub),
+		pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+		ssh.MarshalAuthorizedKey(sshRsaPub),
+		pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)})
+}
+
+func Test_encryptFile(t *testing.T) {
+	scryptLogN = 10
+	defer func() {
+		scryptLogN = 18
+	}()
+	dir, err := ioutil.TempDir("", "*-e-encryption")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	edPub, edPriv, rsaPub, rsaPriv := testKeys(t)
+	_, otherEdPriv, _, _ := testKeys(t)
+
+	tests := []struct {
+		name              string
+		size              int
+		passphrase        string
+		recipients        [][]byte
+		decryptPassphrase string
+		identities        [][]byte
+		modify            func([]byte) []byte
+		wantEncryptErr    error
+		wantErr           error
+		wantPassphrase    bool
+	}{
+		{
+			name:              "passphrase",
+			size:              1000,
+			passphrase:        "secret",
+			decryptPassphrase: "secret",
+			wantPassphrase:    true,
+		},
+		{
+			name:              "empty file",
+			passphrase:        "secret",
+			decryptPassphrase: "secret",
+			wantPassphrase:    true,
+		},
+		{
+			name:       "ed25519 recipient",
+			size:       200 * 1024,
+			recipients: [][]byte{edPub},
+			identities: [][]byte{edPriv},
+		},
+		{
+			name:       "rsa recipient",
+			size:       100,
+			recipients: [][]byte{rsaPub},
+			identities: [][]byte{rsaPriv},
+		},
+		{
+			name:       "second identity matches",
+			size:       100,
+			recipients: [][]byte{edPub, rsaPub},
+			identities: [][]byte{otherEdPriv, rsaPriv},
+		},
+		{
+			name:           "passphrase and recipient",
+			passphrase:     "secret",
+			recipients:     [][]byte{edPub},
+			wantEncryptErr: errors.New("archive can be encrypted either with passphrase or for recipients, not both"),
+		},
+		{
+			name:           "incorrect recipient",
+			recipients:     [][]byte{[]byte("ssh-dss AAAA")},
+			wantEncryptErr: errors.New("incorrect recipient: malformed SSH recipient: \"ssh-dss AAAA\": ssh: no key found"),
+		},
+		{
+			name:              "incorrect passphrase",
+			size:              100,
+			passphrase:        "secret",
+			decryptPassphrase: "public",
+			wantPassphrase:    true,
+			wantErr:           errors.New("archive cannot be decrypted with provided passphrase or identities"),
+		},
+		{
+			name:       "not matching identity",
+			size:       100,
+			recipients: [][]byte{edPub},
+			identities: [][]byte{otherEdPriv, rsaPriv},
+			wantErr:    errors.New("archive cannot be decrypted with provided passphrase or identities"),
+		},
+		{
+			name:           "no passphrase nor identity",
+			size:           100,
+			passphrase:     "secret",
+			wantPassphrase: true,
+			wantErr:        ErrEncrypted,
+		},
+		{
+			name:              "modified payload",
+			size:              100,
+			passphrase:        "secret",
+			decryptPassphrase: "secret",
+			wantPassphrase:    true,
+			modify: func(b []byte) []byte {
+				b[len(b)-1] ^= 1
+				return b
+			},
+			wantErr: errors.New("encrypted archive is corrupted or truncated: failed to decrypt and authenticate payload chunk"),
+		},
+		{
+			name:       "modified header",
+			size:       100,
+			recipients: [][]byte{edPub},
+			identities: [][]byte{edPriv},
+			modify: func(b []byte) []byte {
+				i := bytes.Index(b, []byte("\n--- ")) + len("\n--- ")
+				if b[i] == 'A' {
+					b[i] = 'B'
+				} else {
+					b[i] = 'A'
+				}
+				return b
+			},
+			wantErr: errors.New("unable to decrypt archive: bad header MAC"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			content := make([]byte, tt.size)
+			_, _ = rand.Read(content)
+			src, enc, dec := path.Join(dir, "src"), path.Join(dir, "src.enc"), path.Join(dir, "dec")
+			_ = os.Remove(enc)
+			_ = os.Remove(dec)
+			a.NoError(ioutil.WriteFile(src, content, 0644))
+
+			err := encryptFile(src, enc, tt.passphrase, tt.recipients)
+			if tt.wantEncryptErr != nil {
+				a.EqualError(err, tt.wantEncryptErr.Error())
+				a.NoFileExists(enc)
+				return
+			}
+			a.NoError(err)
+			encrypted, err := isEncryptedArchive(enc)
+			a.NoError(err)
+			a.True(encrypted)
+			needsPassphrase, err := NeedsPassphrase(enc)
+			a.NoError(err)
+			a.Equal(tt.wantPassphrase, needsPassphrase)
+			if tt.modify != nil {
+				b, err := ioutil.ReadFile(enc)
+				a.NoError(err)
+				a.NoError(ioutil.WriteFile(enc, tt.modify(b), 0644))
+			}
+
+			err = decryptFile(enc, dec, tt.decryptPassphrase, tt.identities)
+			if tt.wantErr != nil {
+				if errors.Is(tt.wantErr, ErrEncrypted) {
+					a.True(errors.Is(err, ErrEncrypted))
+				} else {
+					a.EqualError(err, tt.wantErr.Error())
+				}
+				a.NoFileExists(dec)
+				return
+			}
+			a.NoError(err)
+			got, err := ioutil.ReadFile(dec)
+			a.NoError(err)
+			a.True(bytes.Equal(content, got))
+		})
+	}
+}
+
+func TestEnvironment_Export_Encrypted(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "export-encrypted")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	scryptLogN = 10
+	defer func() {
+		scryptLogN = 18
+	}()
+	a := assert.New(t)
+	edPub, edPriv, _, _ := testKeys(t)
+
+	e, err := Create("encrypted")
+	a.NoError(err)
+	archive, err := e.Export(util.UsedConfigurationDirectory, ExportOptions{Recipients: [][]byte{edPub}})
+	a.NoError(err)
+	a.Equal(path.Join(util.UsedConfigurationDirectory, e.Uuid.String()+".zip.enc"), archive)
+	a.NoFileExists(path.Join(util.UsedConfigurationDirectory, e.Uuid.String()+".zip"))
+	a.NoError(os.RemoveAll(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String())))
+
+	needsPassphrase, err := NeedsPassphrase(archive)
+	a.NoError(err)
+	a.False(needsPassphrase)
+	_, err = Import(context.Background(), archive, ImportOptions{})
+	a.True(errors.Is(err, ErrEncrypted), "unexpected error: %v", err)
+
+	got, err := Import(context.Background(), archive, ImportOptions{Identities: [][]byte{edPriv}})
+	a.NoError(err)
+	a.Equal(e.Uuid, got)
+	a.FileExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), util.DefaultEnvironmentConfigFileName))
+	items, err := ioutil.ReadDir(util.UsedTempDirectory)
+	a.NoError(err)
+	a.Empty(items)
+}
+
+func TestEnvironment_Export_ExcludeSecrets(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "export-secrets")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+	_, edPriv, _, _ := testKeys(t)
+
+	e, err := Create("secrets")
+	a.NoError(err)
+	a.NoError(e.Install(context.Background(), InstalledComponentVersion{
+		EnvironmentRef: e.Uuid,
+		Name:           "c1",
+		Type:           "local",
+		Version:        "v1",
+		Image:          "sh",
+		Mounts:         []string{"/terraform"},
+		Shared:         "/shared",
+		Secrets:        []string{"/terraform/*.tfstate*", "/other/secret"},
+	}))
+	envDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String())
+	files := map[string][]byte{
+		"shared/vms_rsa":                                  edPriv,
+		"shared/vms_rsa.pub":                              []byte("ssh-ed25519 AAAA"),
+		"c1/v1/mounts/terraform/terraform.tfstate":        []byte("{}"),
+		"c1/v1/mounts/terraform/terraform.tfstate.backup": []byte("{}"),
+		"c1/v1/mounts/terraform/main.tf":                  []byte(""),
+	}
+	for f, content := range files {
+		a.NoError(os.MkdirAll(path.Dir(path.Join(envDirectory, f)), 0755))
+		a.NoError(ioutil.WriteFile(path.Join(envDirectory, f), content, 0644))
+	}
+
+	archive, err := e.Export(util.UsedConfigurationDirectory, ExportOptions{ExcludeSecrets: true})
+	a.NoError(err)
+	var archived []string
+	a.NoError(archiver.Walk(archive, func(f archiver.File) error {
+		if !f.IsDir() {
+			archived = append(archived, f.Name())
+		}
+		return nil
+	}))
+	a.ElementsMatch([]string{util.DefaultEnvironmentConfigFileName, util.DefaultExportManifestFileName, "vms_rsa.pub", "main.tf"}, archived)
+
+	a.NoError(os.RemoveAll(envDirectory))
+	_, err = Import(context.Background(), archive, ImportOptions{})
+	a.NoError(err)
+	a.FileExists(path.Join(envDirectory, "c1/v1/mounts/terraform/main.tf"))
+	a.NoFileExists(path.Join(envDirectory, "shared/vms_rsa"))
+	a.NoFileExists(path.Join(envDirectory, util.DefaultExportManifestFileName))
+}
@@ -2,19 +2,23 @@ package environment
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/epiphany-platform/cli/internal/logger"
 	"github.com/epiphany-platform/cli/internal/util"
 	"github.com/epiphany-platform/cli/pkg/auth"
-	"github.com/epiphany-platform/cli/pkg/docker"
 
+	"github.com/Masterminds/semver/v3"
 	"github.com/google/uuid"
 	"github.com/mholt/archiver/v3"
 	"github.com/otiai10/copy"
@@ -34,29 +38,74 @@ type InstalledComponentCommand struct {
 	Args        []string          `yaml:"args"`
 }
 
-func (cc *InstalledComponentCommand) RunDocker(image string, workDirectory string, mounts map[string]string, processor func(string) string) error {
-	//TODO add tests
-	for _, v := range mounts {
-		util.EnsureDirectory(v)
-	}
+//RunOptions holds user provided parameters of single run of installed component command
+type RunOptions struct {
+	Interactive bool              // forward stdin to command
+	Tty         bool              // allocate pseudo-TTY for command
+	ExtraArgs   []string          // appended to processed command args
+	Envs        map[string]string // override processed command envs
+}
+
+//job prepares Job for command of installed component version. Command args and envs are processed with
+//processor before user provided options are applied.
+func (cc *InstalledComponentCommand) job(cv *InstalledComponentVersion, processor func(string) (string, error), options RunOptions) (Job, error) {
 	envs := make(map[string]string)
 	for k, v := range cc.Envs {
-		envs[k] = processor(v)
+		pv, err := processor(v)
+		if err != nil {
+			return Job{}, fmt.Errorf("processing env %s failed: %w", k, err)
+		}
+		envs[k] = pv
+	}
+	for k, v := range options.Envs {
+		envs[k] = v
 	}
-	args := make([]string, len(cc.Args))
+	args := make([]string, 0, len(cc.Args)+len(options.ExtraArgs))
 	for _, a := range cc.Args {
-		args = append(args, processor(a))
+		pa, err := processor(a)
+		if err != nil {
+			return Job{}, fmt.Errorf("processing args failed: %w", err)
+		}
+		args = append(args, pa)
 	}
-	dockerJob := &docker.Job{
-		Image:                image,
+	args = append(args, options.ExtraArgs...)
+	return Job{
+		Image:                cv.Image,
+		Digest:               cv.Digest,
 		Command:              cc.Command,
 		Args:                 args,
-		WorkDirectory:        workDirectory,
-		Mounts:               mounts,
+		WorkDirectory:        cv.WorkDirectory,
+		Mounts:               cv.mounts(),
 		EnvironmentVariables: envs,
+		Interactive:          options.Interactive,
+		Tty:                  options.Tty,
+	}, nil
+}
+
+//run executes job with provided runtime and stores information about the run in provided RunRecord.
+//Job is stopped when ctx gets cancelled.
+func run(ctx context.Context, runtime Runtime, job Job, record *RunRecord) error {
+	for _, v := range job.Mounts {
+		util.EnsureDirectory(v)
 	}
-	logger.Debug().Msgf("will try to run docker job %+v", dockerJob)
-	return dockerJob.Run()
+	job.Stdout = record.Stdout()
+	job.Stderr = record.Stderr()
+	record.Command = job.Command
+	record.Args = job.Args
+	record.Started = time.Now()
+	err := runtime.Run(ctx, job)
+	record.Finished = time.Now()
+	var exitErr *ExitError
+	switch {
+	case err == nil:
+		record.ExitCode = 0
+	case errors.As(err, &exitErr):
+		record.ExitCode = exitErr.Code
+		record.Error = err.Error()
+	default:
+		record.Error = err.Error()
+	}
+	return err
 }
 
 //The String method is used to pretty-print InstalledComponentCommand struct
@@ -64,6 +113,12 @@ func (cc *InstalledComponentCommand) String() string {
 	return fmt.Sprintf("    Command:\n     Name %s\n     Description %s\n", cc.Name, cc.Description)
 }
 
+//InstalledComponentOutput holds information about output declared by installed component
+type InstalledComponentOutput struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
 //InstalledComponentVersion struct holds information about installed components with its details.
 type InstalledComponentVersion struct {
 	EnvironmentRef uuid.UUID                   `yaml:"environment_ref"` //TODO try to remove it
@@ -71,40 +126,97 @@ type InstalledComponentVersion struct {
 	Type           string                      `yaml:"type"`
 	Version        string                      `yaml:"version"`
 	Image          string                      `yaml:"image"`
+	Digest         string                      `yaml:"digest,omitempty"` // content digest image was pinned to at install
 	WorkDirectory  string                      `yaml:"workdir"`
 	Mounts         []string                    `yaml:"mounts"`
 	Shared         string                      `yaml:"shared"`
 	Commands       []InstalledComponentCommand `yaml:"commands"`
+	Outputs        []InstalledComponentOutput  `yaml:"outputs,omitempty"`
+	Requires       []string                    `yaml:"requires,omitempty"`
+	Secrets        []string                    `yaml:"secrets,omitempty"` // container paths (or patterns) of secret files
 }
 
-func (cv *InstalledComponentVersion) Run(command string, processor func(string) string) error {
-	//TODO add tests
-	if cv.Type == "docker" {
-		mounts := make(map[string]string)
-		moduleMountPath := path.Join(
+//Run runs named command of installed component version with runtime registered for component type and records
+//the run in runs directory
+func (cv *InstalledComponentVersion) Run(ctx context.Context, command string, processor func(string) (string, error), options RunOptions) error {
+	runtime, err := GetRuntime(cv.Type)
+	if err != nil {
+		return err
+	}
+	for _, cc := range cv.Commands {
+		if cc.Name == command {
+			job, err := cc.job(cv, processor, options)
+			if err != nil {
+				return err
+			}
+			record, err := newRunRecord(cv, command)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				err := record.Close()
+				if err != nil {
+					logger.Error().Err(err).Msg("failed to persist run record")
+				}
+			}()
+			return run(ctx, runtime, job, record)
+		}
+	}
+	return fmt.Errorf("component %s has no command %s", cv.Name, command)
+}
+
+//HasCommand checks if installed component version provides named command
+func (cv *InstalledComponentVersion) HasCommand(command string) bool {
+	for _, cc := range cv.Commands {
+		if cc.Name == command {
+			return true
+		}
+	}
+	return false
+}
+
+//Shell starts interactive shell with runtime of installed component version with all its mounts attached
+func (cv *InstalledComponentVersion) Shell(ctx context.Context, shell string) error {
+	runtime, err := GetRuntime(cv.Type)
+	if err != nil {
+		return err
+	}
+	mounts := cv.mounts()
+	for _, v := range mounts {
+		util.EnsureDirectory(v)
+	}
+	return runtime.Run(ctx, Job{
+		Image:         cv.Image,
+		Digest:        cv.Digest,
+		Entrypoint:    shell,
+		WorkDirectory: cv.WorkDirectory,
+		Mounts:        mounts,
+		Interactive:   true,
+		Tty:           true,
+	})
+}
+
+//mounts returns map of container paths to host directories used by installed component version
+func (cv *InstalledComponentVersion) mounts() map[string]string {
+	mounts := make(map[string]string)
+	moduleMountPath := path.Join(
+		util.UsedEnvironmentDirectory,
+		cv.EnvironmentRef.String(),
+		cv.Name,
+		cv.Version,
+		util.DefaultComponentMountsSubdirectory,
+	)
+	for _, m := range cv.Mounts {
+		mounts[m] = path.Join(moduleMountPath, m)
+	}
+	if cv.Shared != "" {
+		mounts[cv.Shared] = path.Join(
 			util.UsedEnvironmentDirectory,
 			cv.EnvironmentRef.String(),
-			cv.Name,
-			cv.Version,
-			util.DefaultComponentMountsSubdirectory,
+			util.DefaultSharedSubdirectory,
 		)
-		for _, m := range cv.Mounts {
-			mounts[m] = path.Join(moduleMountPath, m)
-		}
-		if cv.Shared != "" {
-			mounts[cv.Shared] = path.Join(
-				util.UsedEnvironmentDirectory,
-				cv.EnvironmentRef.String(),
-				"/shared", //TODO to consts
-			)
-		}
-		for _, cc := range cv.Commands {
-			if cc.Name == command {
-				return cc.RunDocker(cv.Image, cv.WorkDirectory, mounts, processor)
-			}
-		}
 	}
-	return errors.New("nothing to run for this version")
+	return mounts
 }
 
 //The String method is used to pretty-print InstalledComponentVersion struct
@@ -117,41 +229,24 @@ func (cv *InstalledComponentVersion) String() string {
 	return b.String()
 }
 
-func (cv *InstalledComponentVersion) Download() error {
-	//TODO add tests
-	if cv.Type == "docker" {
-		dockerImage := &docker.Image{Name: cv.Image}
-		found, err := dockerImage.IsPulled()
-		if err != nil {
-			return err
-		}
-		if found {
-			logger.Debug().Msg("image is already present, no need to download") //TODO consider --force-download switch
-			return nil
-		}
-		logs, err := dockerImage.Pull()
-		cv.PersistLogs(logs)
-		if err != nil {
-			return err
-		}
-		return nil
+//Download prepares installed component version to be run with runtime registered for its type
+func (cv *InstalledComponentVersion) Download(ctx context.Context) error {
+	runtime, err := GetRuntime(cv.Type)
+	if err != nil {
+		return err
 	}
-	return nil
+	return runtime.Download(ctx, cv)
 }
 
-func (cv *InstalledComponentVersion) PersistLogs(logs string) { //TODO change to zerolog
-	logsPath := path.Join(
-		util.UsedEnvironmentDirectory,
-		cv.EnvironmentRef.String(),
-		cv.Name,
-		cv.Version,
-		util.DefaultComponentRunsSubdirectory,
-		fmt.Sprintf("%s.log", time.Now().Format("20060102-150405.000MST")),
-	)
-	err := ioutil.WriteFile(logsPath, []byte(logs), 0644)
+//PersistLogs writes logs (e.g. of image pull) into new file in runs subdirectory of component version
+func (cv *InstalledComponentVersion) PersistLogs(logs string) error {
+	err := os.MkdirAll(cv.runsDirectory(), 0755)
 	if err != nil {
-		logger.Panic().Err(err).Msg("failed to write file")
+		return err
 	}
+	logsPath := path.Join(cv.runsDirectory(), fmt.Sprintf("%s.log", time.Now().Format("20060102-150405.000MST")))
+	logger.Debug().Msgf("will try to write logs to file %s", logsPath)
+	return ioutil.WriteFile(logsPath, []byte(logs), 0644)
 }
 
 type SshConfig struct {
@@ -164,6 +259,9 @@ type Environment struct {
 	Uuid      uuid.UUID                   `yaml:"uuid"`
 	Installed []InstalledComponentVersion `yaml:"installed"`
 	SshConfig SshConfig                   `yaml:"ssh-config,omitempty"`
+	Outputs   map[string]Outputs          `yaml:"outputs,omitempty"` // collected outputs by component name
+
+	AutoSnapshot bool `yaml:"auto-snapshot,omitempty"` // take snapshot before every run of component command or apply
 }
 
 //Save updated Environment to file
@@ -171,12 +269,17 @@ func (e *Environment) Save() error {
 	if e.Uuid == uuid.Nil {
 		return errors.New(fmt.Sprintf("unexpected UUID on Save: %s", e.Uuid))
 	}
+	return e.writeConfig(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()))
+}
+
+//writeConfig writes Environment config file into provided directory
+func (e *Environment) writeConfig(directory string) error {
 	logger.Debug().Msgf("will try to marshal environment %+v", e)
 	data, err := yaml.Marshal(e)
 	if err != nil {
 		return err
 	}
-	ep := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), util.DefaultEnvironmentConfigFileName)
+	ep := path.Join(directory, util.DefaultEnvironmentConfigFileName)
 	logger.Debug().Msgf("will try to write marshaled data to file %s", ep)
 	err = ioutil.WriteFile(ep, data, 0644)
 	if err != nil {
@@ -196,25 +299,221 @@ func (e *Environment) String() string {
 	return b.String()
 }
 
-func (e *Environment) Install(newComponent InstalledComponentVersion) error {
+func (e *Environment) Install(ctx context.Context, newComponent InstalledComponentVersion) error {
 	//TODO add tests
 	for _, ic := range e.Installed {
 		if ic.Name == newComponent.Name && ic.Version == newComponent.Version {
 			return errors.New("this version of component is already installed in environment")
 		}
 	}
-	e.Installed = append(e.Installed, newComponent)
+	err := e.checkRequirements(newComponent)
+	if err != nil {
+		return err
+	}
 	newComponentRunsDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), newComponent.Name, newComponent.Version, util.DefaultComponentRunsSubdirectory)
 	newComponentMountsDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), newComponent.Name, newComponent.Version, util.DefaultComponentMountsSubdirectory)
 	util.EnsureDirectory(newComponentRunsDirectory)
 	util.EnsureDirectory(newComponentMountsDirectory)
-	err := newComponent.Download()
+	// download can pin image of new component so it is added to installed components afterwards
+	err = newComponent.Download(ctx)
 	if err != nil {
 		return err
 	}
+	e.Installed = append(e.Installed, newComponent)
 	return e.Save()
 }
 
+//Verify checks if images of installed component versions still have content digests they were pinned to at
+//install. Only components of runtimes implementing ImageVerifier are checked. If name is not empty only versions of
+//named component are checked.
+func (e *Environment) Verify(ctx context.Context, name string) ([]ImageVerification, error) {
+	var result []ImageVerification
+	found := false
+	for i := range e.Installed {
+		cv := &e.Installed[i]
+		if name != "" && cv.Name != name {
+			continue
+		}
+		found = true
+		runtime, err := GetRuntime(cv.Type)
+		if err != nil {
+			return nil, err
+		}
+		verifier, ok := runtime.(ImageVerifier)
+		if !ok {
+			logger.Debug().Msgf("runtime of component %s does not pin images, skipping it", cv.Name)
+			continue
+		}
+		result = append(result, verifier.VerifyImage(ctx, cv))
+	}
+	if name != "" && !found {
+		return nil, errors.New("no such component installed")
+	}
+	return result, nil
+}
+
+//Uninstall removes installed component version from environment together with its mounts and runs directories.
+//If version is empty there has to be exactly one version of named component installed.
+func (e *Environment) Uninstall(name, version string) error {
+	i, err := e.indexOfInstalled(name, version)
+	if err != nil {
+		return err
+	}
+	ic := e.Installed[i]
+	if _, err := e.indexOfInstalled(ic.Name, ""); err == nil {
+		// this is the only installed version of component
+		if dependents := e.requiredBy(ic.Name); len(dependents) > 0 {
+			return fmt.Errorf("component %s is required by %s", ic.Name, strings.Join(dependents, ", "))
+		}
+	}
+	e.Installed = append(e.Installed[:i], e.Installed[i+1:]...)
+	if _, err := e.GetComponentByName(ic.Name); err != nil {
+		// outputs are kept as long as any version of component is installed
+		delete(e.Outputs, ic.Name)
+	}
+	err = e.Save()
+	if err != nil {
+		return err
+	}
+	return e.removeComponentDirectory(ic.Name, ic.Version)
+}
+
+//Upgrade replaces installed version of component with newComponent. If migrateMounts is true content of
+//mounts directory of previously installed version is copied to mounts directory of new version. Runs history
+//is moved to new version. Version which is not newer than installed one is refused unless force is set.
+func (e *Environment) Upgrade(ctx context.Context, newComponent InstalledComponentVersion, migrateMounts, force bool) error {
+	i, err := e.indexOfInstalled(newComponent.Name, "")
+	if err != nil {
+		return err
+	}
+	old := e.Installed[i]
+	if old.Version == newComponent.Version {
+		return errors.New("this version of component is already installed in environment")
+	}
+	if !force {
+		err = checkNewer(old.Version, newComponent.Version)
+		if err != nil {
+			return err
+		}
+	}
+	err = e.checkRequirements(newComponent)
+	if err != nil {
+		return err
+	}
+	// image is downloaded first, so that nothing is created in environment if it is not available
+	err = newComponent.Download(ctx)
+	if err != nil {
+		return err
+	}
+
+	oldComponentDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), old.Name, old.Version)
+	newComponentDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), newComponent.Name, newComponent.Version)
+	// partly created directory of new version is removed if upgrade fails
+	cleanup := func() {
+		if err := e.removeComponentDirectory(newComponent.Name, newComponent.Version); err != nil {
+			logger.Warn().Err(err).Msgf("unable to remove directory %s", newComponentDirectory)
+		}
+	}
+	util.EnsureDirectory(newComponentDirectory)
+	if migrateMounts {
+		oldMountsDirectory := path.Join(oldComponentDirectory, util.DefaultComponentMountsSubdirectory)
+		logger.Debug().Msgf("will try to copy mounts from %s to %s", oldMountsDirectory, newComponentDirectory)
+		err = copy.Copy(oldMountsDirectory, path.Join(newComponentDirectory, util.DefaultComponentMountsSubdirectory))
+		if err != nil {
+			cleanup()
+			return err
+		}
+	}
+	util.EnsureDirectory(path.Join(newComponentDirectory, util.DefaultComponentMountsSubdirectory))
+
+	// runs history of old version is kept, records contain version they were run with
+	oldRunsDirectory := path.Join(oldComponentDirectory, util.DefaultComponentRunsSubdirectory)
+	newRunsDirectory := path.Join(newComponentDirectory, util.DefaultComponentRunsSubdirectory)
+	logger.Debug().Msgf("will try to move runs from %s to %s", oldRunsDirectory, newRunsDirectory)
+	err = os.Rename(oldRunsDirectory, newRunsDirectory)
+	if err != nil && !os.IsNotExist(err) {
+		cleanup()
+		return err
+	}
+	util.EnsureDirectory(newRunsDirectory)
+
+	e.Installed[i] = newComponent
+	err = e.Save()
+	if err != nil {
+		e.Installed[i] = old
+		if err2 := os.Rename(newRunsDirectory, oldRunsDirectory); err2 != nil {
+			logger.Error().Err(err2).Msgf("unable to move runs back to %s", oldRunsDirectory)
+		}
+		cleanup()
+		return err
+	}
+	return e.removeComponentDirectory(old.Name, old.Version)
+}
+
+//checkNewer returns error if new version is not higher than installed one. Versions which are not semantic
+//versions cannot be compared and are allowed.
+func checkNewer(installed, new string) error {
+	iv, err := semver.NewVersion(installed)
+	if err != nil {
+		logger.Warn().Msgf("unable to compare version %s with %s, it is not semantic version", installed, new)
+		return nil
+	}
+	nv, err := semver.NewVersion(new)
+	if err != nil {
+		logger.Warn().Msgf("unable to compare version %s with %s, it is not semantic version", new, installed)
+		return nil
+	}
+	if !nv.GreaterThan(iv) {
+		return fmt.Errorf("version %s is not newer than installed version %s, upgrade has to be forced", new, installed)
+	}
+	return nil
+}
+
+//indexOfInstalled returns index of installed component with provided name and version. If version is empty
+//it expects only one version of component to be installed.
+func (e *Environment) indexOfInstalled(name, version string) (int, error) {
+	found := -1
+	for i, ic := range e.Installed {
+		if ic.Name != name {
+			continue
+		}
+		if version != "" && ic.Version != version {
+			continue
+		}
+		if found >= 0 {
+			return -1, fmt.Errorf("multiple versions of component %s installed, version has to be specified", name)
+		}
+		found = i
+	}
+	if found < 0 {
+		return -1, errors.New("no such component installed")
+	}
+	return found, nil
+}
+
+//removeComponentDirectory removes directory of component version and also component directory if it is empty
+func (e *Environment) removeComponentDirectory(name, version string) error {
+	componentDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), name)
+	logger.Debug().Msgf("will try to remove directory %s", path.Join(componentDirectory, version))
+	err := os.RemoveAll(path.Join(componentDirectory, version))
+	if err != nil {
+		return err
+	}
+	items, err := ioutil.ReadDir(componentDirectory)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		return os.Remove(componentDirectory)
+	}
+	return nil
+}
+
+//SharedDirectory returns path of directory shared by all components installed in environment
+func (e *Environment) SharedDirectory() string {
+	return path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), util.DefaultSharedSubdirectory)
+}
+
 //GetComponentByName returns first InstalledComponentVersion found by name
 func (e *Environment) GetComponentByName(name string) (*InstalledComponentVersion, error) {
 	for _, ic := range e.Installed {
@@ -229,8 +528,12 @@ func (e *Environment) AddRsaKeyPair(rsaKeyPair auth.RsaKeyPair) {
 	e.SshConfig.RsaKeyPair = rsaKeyPair
 }
 
-//Create new environment with given name
+//Create new environment with given name, names of environments have to be unique
 func Create(name string) (*Environment, error) {
+	err := checkNameUnique(name, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
 	return create(name, uuid.New())
 }
 
@@ -262,7 +565,12 @@ func GetAll() ([]*Environment, error) {
 	for _, i := range items {
 		logger.Debug().Msgf("entered directory %s", i.Name())
 		if i.IsDir() {
-			e, err := Get(uuid.MustParse(i.Name()))
+			id, err := uuid.Parse(i.Name())
+			if err != nil {
+				logger.Warn().Msgf("directory %s is not named with environment id and is skipped", i.Name())
+				continue
+			}
+			e, err := Get(id)
 			if err == nil {
 				environments = append(environments, e)
 			} else {
@@ -302,22 +610,26 @@ func Get(uuid uuid.UUID) (*Environment, error) {
 
 // Copy environment directory to a temporary location without text log files
 func (e *Environment) copyDirectoryForExport() (string, error) {
+	return e.copyDirectory(false)
+}
+
+//copyDirectory copies environment directory to a new temporary directory, text log files are copied only if
+//withLogs is set. Temporary directory is returned, the copy is its subdirectory named by environment id. Each call
+//uses own temporary directory, so concurrent copies of the same environment do not interfere.
+func (e *Environment) copyDirectory(withLogs bool) (string, error) {
 
 	opt := copy.Options{
 		Skip: func(src string) (bool, error) {
-			return strings.HasSuffix(src, ".log"), nil
+			return !withLogs && strings.HasSuffix(src, ".log"), nil
 		},
 	}
-	destDir := path.Join(util.UsedTempDirectory, e.Uuid.String())
-
-	// Make sure there are no files from previous runs
-	err := os.RemoveAll(destDir)
+	tempDir, err := ioutil.TempDir(util.UsedTempDirectory, e.Uuid.String()+"-*")
 	if err != nil {
 		return "", err
 	}
 
-	err = copy.Copy(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()), destDir, opt)
-	return destDir, err
+	err = copy.Copy(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()), path.Join(tempDir, e.Uuid.String()), opt)
+	return tempDir, err
 }
 
 // IsExisting checks if environment with specified id exists
@@ -337,79 +649,402 @@ func IsExisting(uuid uuid.UUID) (bool, error) {
 	return isEnvValid, nil
 }
 
-// Export (archive) an environment
-func (e *Environment) Export(dstDir string) error {
+//moveTo assigns new id to environment and all its installed components
+func (e *Environment) moveTo(id uuid.UUID) {
+	e.Uuid = id
+	for i := range e.Installed {
+		e.Installed[i].EnvironmentRef = id
+	}
+}
 
-	// Make a temporary copy of the environment directory with cleaned logs up
-	envTempPath, err := e.copyDirectoryForExport()
+//Rename changes name of environment
+func (e *Environment) Rename(name string) error {
+	if name == "" {
+		return errors.New("environment name cannot be empty")
+	}
+	err := checkNameUnique(name, e.Uuid)
 	if err != nil {
 		return err
 	}
+	logger.Debug().Msgf("will try to rename environment %s from %s to %s", e.Uuid.String(), e.Name, name)
+	e.Name = name
+	return e.Save()
+}
 
-	// Final archive name is envID + .zip extension
-	err = archiver.Archive([]string{envTempPath}, path.Join(dstDir, e.Uuid.String()+".zip"))
+//Clone copies environment directory (with runs history but without snapshots) into new environment with newly
+//generated id and provided name. Installed components of clone refer to the new environment.
+func (e *Environment) Clone(name string) (*Environment, error) {
+	if name == "" {
+		return nil, errors.New("environment name cannot be empty")
+	}
+	err := checkNameUnique(name, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	// config is read again, so that clone does not share installed components with e
+	clone, err := Get(e.Uuid)
+	if err != nil {
+		return nil, err
+	}
+	clone.moveTo(uuid.New())
+	clone.Name = name
+	logger.Debug().Msgf("will try to clone environment %s into %s", e.Uuid.String(), clone.Uuid.String())
+
+	staging, err := ioutil.TempDir(util.UsedTempDirectory, "clone-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(staging)
+	}()
+	cloned := path.Join(staging, clone.Uuid.String())
+	err = copy.Copy(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()), cloned)
+	if err != nil {
+		return nil, err
+	}
+	err = clone.writeConfig(cloned)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Rename(cloned, path.Join(util.UsedEnvironmentDirectory, clone.Uuid.String()))
+	if err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
+
+//Delete removes environment directory together with its snapshots and files left in temporary directory.
+//Environment directory is moved out of environments directory first, so that it is never listed partially removed.
+func (e *Environment) Delete() error {
+	logger.Debug().Msgf("will try to delete environment %s", e.Uuid.String())
+	staging, err := ioutil.TempDir(util.UsedTempDirectory, "delete-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(staging)
+	}()
+	err = os.Rename(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()), path.Join(staging, e.Uuid.String()))
 	if err != nil {
 		return err
 	}
+	err = os.RemoveAll(snapshotsDirectory(e.Uuid))
+	if err != nil {
+		return err
+	}
+	// temporary copies made by export and snapshots are named with environment id
+	leftovers, err := filepath.Glob(path.Join(util.UsedTempDirectory, e.Uuid.String()+"*"))
+	if err != nil {
+		return err
+	}
+	for _, l := range leftovers {
+		logger.Debug().Msgf("will remove temporary file %s", l)
+		err = os.RemoveAll(l)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//ExportOptions configures Export of environment
+type ExportOptions struct {
+	Passphrase     string   // encrypt archive with key derived from passphrase
+	Recipients     [][]byte // encrypt archive for SSH public keys in authorized_keys format
+	ExcludeSecrets bool     // omit private keys and secret files declared by installed components
+}
+
+//exportManifest is written to root of exported environment directory when some files were omitted from archive
+type exportManifest struct {
+	ExcludedSecrets []string `yaml:"excluded-secrets"` // paths relative to environment directory
+}
 
+// Export (archive) an environment into dstDir and return path of created archive. Archive is encrypted if
+// passphrase or recipients are provided.
+func (e *Environment) Export(dstDir string, options ExportOptions) (string, error) {
+
+	// Make a temporary copy of the environment directory with cleaned logs up
+	tempDir, err := e.copyDirectoryForExport()
 	// Remove temporary directory
 	defer func() {
-		_ = os.RemoveAll(envTempPath)
+		_ = os.RemoveAll(tempDir)
 	}()
+	if err != nil {
+		return "", err
+	}
+	envTempPath := path.Join(tempDir, e.Uuid.String())
 
-	return nil
+	if options.ExcludeSecrets {
+		err = e.excludeSecrets(envTempPath)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Final archive name is envID + .zip extension
+	archive := path.Join(dstDir, e.Uuid.String()+".zip")
+	encrypt := options.Passphrase != "" || len(options.Recipients) > 0
+	if encrypt {
+		archive = envTempPath + ".zip"
+	}
+	err = archiver.Archive([]string{envTempPath}, archive)
+	if err != nil {
+		return "", err
+	}
+	if !encrypt {
+		return archive, nil
+	}
+
+	encrypted := path.Join(dstDir, e.Uuid.String()+".zip"+encryptedArchiveExtension)
+	logger.Debug().Msgf("will try to encrypt archive into %s", encrypted)
+	err = encryptFile(archive, encrypted, options.Passphrase, options.Recipients)
+	if err != nil {
+		return "", err
+	}
+	return encrypted, nil
 }
 
-// Import (extract) an environment
-func Import(srcFile string) (uuid.UUID, error) {
-	// Check if environment config exists in zip archive
-	// before export and verify its content
-	var envConfig *Environment
-	isFound := false
-	err := archiver.Walk(srcFile, func(f archiver.File) error {
-		if f.Name() == util.DefaultEnvironmentConfigFileName {
-			isFound = true
-			configContent, err := ioutil.ReadAll(f)
-			if err != nil {
-				return errors.New("unable to read environment config")
+//excludeSecrets removes secret files from copy of environment directory and records them in export manifest
+func (e *Environment) excludeSecrets(envTempPath string) error {
+	secrets, err := e.secretFiles()
+	if err != nil {
+		return err
+	}
+	for _, s := range secrets {
+		logger.Info().Msgf("secret %s is excluded from export", s)
+		err = os.RemoveAll(path.Join(envTempPath, s))
+		if err != nil {
+			return err
+		}
+	}
+	data, err := yaml.Marshal(exportManifest{ExcludedSecrets: secrets})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path.Join(envTempPath, util.DefaultExportManifestFileName), data, 0644)
+}
+
+//secretFiles returns paths (relative to environment directory) of files containing private keys and of files
+//matching secrets declared by installed components
+func (e *Environment) secretFiles() ([]string, error) {
+	envDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String())
+	found := make(map[string]bool)
+	for _, cv := range e.Installed {
+		mounts := cv.mounts()
+		for _, secret := range cv.Secrets {
+			p, ok := hostPath(mounts, secret)
+			if !ok {
+				logger.Warn().Msgf("secret %s of component %s is not in any of its mounts", secret, cv.Name)
+				continue
 			}
-			envConfig = &Environment{}
-			err = yaml.Unmarshal(configContent, envConfig)
+			matches, err := filepath.Glob(p)
 			if err != nil {
-				return errors.New("cannot unmarshal config")
+				return nil, err
 			}
-			if envConfig.Uuid == uuid.Nil {
-				return errors.New("environment id is missing in the config")
+			for _, m := range matches {
+				rel, err := filepath.Rel(envDirectory, m)
+				if err != nil {
+					return nil, err
+				}
+				found[rel] = true
+			}
+		}
+	}
+	err := filepath.Walk(envDirectory, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.Mode().IsRegular() && isPrivateKeyFile(p) {
+			rel, err := filepath.Rel(envDirectory, p)
+			if err != nil {
+				return err
 			}
+			found[rel] = true
 		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	secrets := make([]string, 0, len(found))
+	for s := range found {
+		secrets = append(secrets, s)
+	}
+	sort.Strings(secrets)
+	return secrets, nil
+}
+
+//isPrivateKeyFile checks if file starts with PEM or OpenSSH private key block
+func isPrivateKeyFile(file string) bool {
+	f, err := os.Open(file)
+	if err != nil {
+		return false
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	b := make([]byte, 128)
+	n, _ := io.ReadFull(f, b)
+	b = bytes.TrimSpace(b[:n])
+	return bytes.HasPrefix(b, []byte("-----BEGIN ")) && bytes.Contains(b, []byte("PRIVATE KEY-----"))
+}
+
+//ImportOptions configures Import of environment archive
+type ImportOptions struct {
+	Passphrase string   // passphrase archive was encrypted with
+	Identities [][]byte // SSH private keys of recipients archive was encrypted for
+	AsNew      bool     // import environment under new id, so that it does not conflict with existing one
+	Name       string   // name of imported environment, name from archive is used if empty (made unique if AsNew)
+	Overwrite  bool     // replace existing environment with the same id, it is snapshotted first
+}
+
+// Import (extract) an environment. Encrypted archive is decrypted with passphrase or one of identities first.
+// Environment which already exists is refused unless it is imported as new or overwritten.
+func Import(ctx context.Context, srcFile string, options ImportOptions) (uuid.UUID, error) {
+	if options.AsNew && options.Overwrite {
+		return uuid.Nil, errors.New("environment cannot be imported as new and overwrite existing one at the same time")
+	}
+	encrypted, err := isEncryptedArchive(srcFile)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if encrypted {
+		decrypted := path.Join(util.UsedTempDirectory, uuid.New().String()+".zip")
+		defer func() {
+			_ = os.Remove(decrypted)
+		}()
+		logger.Debug().Msgf("will try to decrypt archive %s into %s", srcFile, decrypted)
+		err = decryptFile(srcFile, decrypted, options.Passphrase, options.Identities)
+		if errors.Is(err, ErrEncrypted) {
+			return uuid.Nil, fmt.Errorf("archive %s %w, passphrase or identity is required to decrypt it", srcFile, err)
+		}
+		if err != nil {
+			return uuid.Nil, err
+		}
+		srcFile = decrypted
+	}
+
+	// Extract archive to staging directory, so that nothing is written to environments directory
+	// before archive content is validated
+	staging, err := ioutil.TempDir(util.UsedTempDirectory, "import-*")
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(staging)
+	}()
+	logger.Debug().Msgf("will try to extract archive %s into %s", srcFile, staging)
+	envUuid, err := extractEnvironmentArchive(srcFile, staging)
 	if err != nil {
 		return uuid.Nil, err
-	} else if !isFound {
+	}
+	extracted := path.Join(staging, envUuid.String())
+
+	// Check if environment config exists in archive and verify its content
+	configContent, err := ioutil.ReadFile(path.Join(extracted, util.DefaultEnvironmentConfigFileName))
+	if os.IsNotExist(err) {
 		return uuid.Nil, errors.New("missing environment config file")
+	} else if err != nil {
+		return uuid.Nil, errors.New("unable to read environment config")
+	}
+	envConfig := &Environment{}
+	err = yaml.Unmarshal(configContent, envConfig)
+	if err != nil {
+		return uuid.Nil, errors.New("cannot unmarshal config")
+	}
+	if envConfig.Uuid == uuid.Nil {
+		return uuid.Nil, errors.New("environment id is missing in the config")
+	}
+	if envConfig.Uuid != envUuid {
+		return uuid.Nil, fmt.Errorf("environment id %s in the config does not match directory %s in archive", envConfig.Uuid.String(), envUuid.String())
+	}
+	for _, cmp := range envConfig.Installed {
+		if cmp.EnvironmentRef != envUuid {
+			return uuid.Nil, fmt.Errorf("installed component %s refers to other environment %s", cmp.Name, cmp.EnvironmentRef.String())
+		}
+	}
+
+	if options.AsNew {
+		envConfig.moveTo(uuid.New())
+		logger.Debug().Msgf("environment %s will be imported as new environment %s", envUuid.String(), envConfig.Uuid.String())
+		renamed := path.Join(staging, envConfig.Uuid.String())
+		err = os.Rename(extracted, renamed)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		extracted = renamed
+	}
+	if options.Name != "" {
+		envConfig.Name = options.Name
+	} else if options.AsNew {
+		envConfig.Name, err = freeName(envConfig.Name, "imported")
+		if err != nil {
+			return uuid.Nil, err
+		}
 	}
 
 	isExisting, err := IsExisting(envConfig.Uuid)
 	if err != nil {
 		return uuid.Nil, err
-	} else if isExisting {
+	}
+	envDirectory := path.Join(util.UsedEnvironmentDirectory, envConfig.Uuid.String())
+	if _, err = os.Stat(envDirectory); err == nil {
+		isExisting = true
+	}
+	if isExisting && !options.Overwrite {
 		return uuid.Nil, fmt.Errorf("environment with id %s already exists", envConfig.Uuid.String())
 	}
+	err = checkNameUnique(envConfig.Name, envConfig.Uuid)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if isExisting {
+		existing, err := Get(envConfig.Uuid)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("existing environment %s is not valid", envConfig.Uuid.String())
+			existing = &Environment{Uuid: envConfig.Uuid, Name: envConfig.Name}
+		}
+		s, err := existing.CreateSnapshot("before overwrite by import", false)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("unable to snapshot existing environment before overwrite: %w", err)
+		}
+		logger.Info().Msgf("existing environment %s saved as snapshot %d", envConfig.Uuid.String(), s.Version)
+	}
+
+	// Warn about secrets which were excluded on export
+	manifestFile := path.Join(extracted, util.DefaultExportManifestFileName)
+	if b, err := ioutil.ReadFile(manifestFile); err == nil {
+		manifest := &exportManifest{}
+		if err = yaml.Unmarshal(b, manifest); err != nil {
+			logger.Warn().Err(err).Msg("unable to read export manifest")
+		}
+		for _, s := range manifest.ExcludedSecrets {
+			logger.Warn().Msgf("secret %s was excluded on export and has to be restored manually", s)
+		}
+		_ = os.Remove(manifestFile)
+	}
 
-	// Unarchive specified file
-	err = archiver.Unarchive(srcFile, util.UsedEnvironmentDirectory)
+	// Move validated environment into place, so that logs of downloads can be persisted in it
+	backup := path.Join(staging, "previous")
+	err = replaceDirectory(envDirectory, extracted, backup)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
-	// Download all Docker images for installed components
-	for _, cmp := range envConfig.Installed {
-		err = cmp.Download()
+	// Download all Docker images for installed components and save digests they were pinned to. Environment
+	// directory is brought back to its previous state if it fails.
+	for i := range envConfig.Installed {
+		err = envConfig.Installed[i].Download(ctx)
 		if err != nil {
+			restoreDirectory(envDirectory, backup)
 			return uuid.Nil, err
 		}
 	}
+	err = envConfig.writeConfig(envDirectory)
+	if err != nil {
+		restoreDirectory(envDirectory, backup)
+		return uuid.Nil, err
+	}
 
 	return envConfig.Uuid, nil
 }
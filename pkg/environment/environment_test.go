@@ -2,6 +2,7 @@ package environment
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -208,11 +209,10 @@ func TestGetAll(t *testing.T) {
 		configContent []byte
 	}
 	tests := []struct {
-		name        string
-		mocked      []mocked
-		want        []*Environment
-		wantErr     error
-		shouldPanic bool
+		name    string
+		mocked  []mocked
+		want    []*Environment
+		wantErr error
 	}{
 		{
 			name: "correct",
@@ -244,8 +244,7 @@ installed: []`),
 					Installed: []InstalledComponentVersion{},
 				},
 			},
-			wantErr:     nil,
-			shouldPanic: false,
+			wantErr: nil,
 		},
 		{
 			name: "subdirectory name not uuid",
@@ -265,8 +264,14 @@ uuid: 4af1705c-c48f-4ca2-be08-53c673da835c
 installed: []`),
 				},
 			},
-			wantErr:     errors.New("uuid: Parse(incorrect-directory-name): invalid UUID length: 24"),
-			shouldPanic: true,
+			want: []*Environment{
+				{
+					Name:      "e2",
+					Uuid:      uuid.MustParse("45764648-162a-4526-bdd0-71a438fd6ceb"),
+					Installed: []InstalledComponentVersion{},
+				},
+			},
+			wantErr: nil,
 		},
 		{
 			name: "incorrect config file name",
@@ -293,8 +298,7 @@ installed: []`),
 					Installed: []InstalledComponentVersion{},
 				},
 			},
-			wantErr:     nil,
-			shouldPanic: false,
+			wantErr: nil,
 		},
 		{
 			name: "incorrect config file content",
@@ -319,8 +323,7 @@ installed: []`),
 					Installed: []InstalledComponentVersion{},
 				},
 			},
-			wantErr:     nil,
-			shouldPanic: false,
+			wantErr: nil,
 		},
 	}
 	for _, tt := range tests {
@@ -345,17 +348,13 @@ installed: []`),
 				}
 			}
 
-			if tt.shouldPanic {
-				a.PanicsWithValue(tt.wantErr.Error(), func() { _, _ = GetAll() })
+			got, err := GetAll()
+			if tt.wantErr == nil {
+				a.NoError(err)
 			} else {
-				got, err := GetAll()
-				if tt.wantErr == nil {
-					a.NoError(err)
-				} else {
-					a.EqualError(err, tt.wantErr.Error())
-				}
-				a.Truef(reflect.DeepEqual(got, tt.want), "got = %#v, want = %#v", got, tt.want)
+				a.EqualError(err, tt.wantErr.Error())
 			}
+			a.Truef(reflect.DeepEqual(got, tt.want), "got = %#v, want = %#v", got, tt.want)
 		})
 	}
 }
@@ -682,6 +681,112 @@ func TestIsExisting(t *testing.T) {
 	}
 }
 
+func TestEnvironment_Clone(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "clone")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	e, err := Create("original")
+	a.NoError(err)
+	e.Installed = []InstalledComponentVersion{{EnvironmentRef: e.Uuid, Name: "c1", Version: "v1", Image: "sh"}}
+	a.NoError(e.Save())
+	sharedFile := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "shared", "state.yaml")
+	a.NoError(os.MkdirAll(path.Dir(sharedFile), 0755))
+	a.NoError(ioutil.WriteFile(sharedFile, []byte("state"), 0644))
+
+	_, err = e.Clone("")
+	a.EqualError(err, "environment name cannot be empty")
+
+	clone, err := e.Clone("copy")
+	a.NoError(err)
+	a.NotEqual(e.Uuid, clone.Uuid)
+	a.Equal(e.Uuid, e.Installed[0].EnvironmentRef)
+
+	got, err := Get(clone.Uuid)
+	a.NoError(err)
+	a.Equal("copy", got.Name)
+	if a.Len(got.Installed, 1) {
+		a.Equal(clone.Uuid, got.Installed[0].EnvironmentRef)
+	}
+	b, err := ioutil.ReadFile(path.Join(util.UsedEnvironmentDirectory, clone.Uuid.String(), "shared", "state.yaml"))
+	a.NoError(err)
+	a.Equal("state", string(b))
+	original, err := Get(e.Uuid)
+	a.NoError(err)
+	a.Equal("original", original.Name)
+	items, err := ioutil.ReadDir(util.UsedTempDirectory)
+	a.NoError(err)
+	a.Empty(items)
+}
+
+func TestEnvironment_Delete(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "delete")
+	util.UsedSnapshotsDirectory = path.Join(util.UsedConfigurationDirectory, util.DefaultSnapshotsSubdirectory)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	e, err := Create("deleted")
+	a.NoError(err)
+	kept, err := Create("kept")
+	a.NoError(err)
+	_, err = e.CreateSnapshot("", false)
+	a.NoError(err)
+	leftover := path.Join(util.UsedTempDirectory, e.Uuid.String()+".zip")
+	a.NoError(ioutil.WriteFile(leftover, []byte("zip"), 0644))
+
+	a.NoError(e.Delete())
+	a.NoDirExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()))
+	a.NoDirExists(snapshotsDirectory(e.Uuid))
+	a.NoFileExists(leftover)
+	a.DirExists(path.Join(util.UsedEnvironmentDirectory, kept.Uuid.String()))
+	items, err := ioutil.ReadDir(util.UsedTempDirectory)
+	a.NoError(err)
+	a.Empty(items)
+	a.Error(e.Delete())
+}
+
+func TestEnvironment_Rename(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "rename")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	e, err := Create("old")
+	a.NoError(err)
+	a.EqualError(e.Rename(""), "environment name cannot be empty")
+	a.NoError(e.Rename("new"))
+	got, err := Get(e.Uuid)
+	a.NoError(err)
+	a.Equal("new", got.Name)
+}
+
+func TestEnvironment_copyDirectory(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "copy")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+	e, err := Create("copy")
+	a.NoError(err)
+
+	first, err := e.copyDirectory(true)
+	a.NoError(err)
+	second, err := e.copyDirectory(false)
+	a.NoError(err)
+	a.NotEqual(first, second)
+	a.FileExists(path.Join(first, e.Uuid.String(), util.DefaultEnvironmentConfigFileName))
+	a.FileExists(path.Join(second, e.Uuid.String(), util.DefaultEnvironmentConfigFileName))
+
+	a.NoError(e.Delete())
+	a.NoDirExists(first)
+	a.NoDirExists(second)
+}
+
 func TestExport(t *testing.T) {
 	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "export")
 	defer func() {
@@ -732,7 +837,7 @@ func TestExport(t *testing.T) {
 					t.Fatal(err)
 				}
 			}
-			err = exportEnv.Export(tt.destDir)
+			_, err = exportEnv.Export(tt.destDir, ExportOptions{})
 			if tt.wantErr == nil {
 				a.NoError(err)
 				a.FileExists(path.Join(tt.destDir, exportEnv.Uuid.String()+".zip"))
@@ -746,7 +851,7 @@ func TestExport(t *testing.T) {
 }
 
 func TestImport(t *testing.T) {
-	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "import")
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "import")
 	defer func() {
 		_ = os.RemoveAll(util.UsedConfigurationDirectory)
 	}()
@@ -782,7 +887,7 @@ func TestImport(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			a := assert.New(t)
-			got, err := Import(tt.from)
+			got, err := Import(context.Background(), tt.from, ImportOptions{})
 			if tt.wantErr == nil {
 				a.NoError(err)
 				a.DirExists(path.Join(util.UsedEnvironmentDirectory, got.String()))
@@ -794,3 +899,192 @@ func TestImport(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvironment_Uninstall(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "env-uninstall")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+
+	tests := []struct {
+		name          string
+		installed     []InstalledComponentVersion
+		componentName string
+		version       string
+		wantInstalled []InstalledComponentVersion
+		wantErr       error
+	}{
+		{
+			name: "by name",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+				{Name: "c2", Version: "v1"},
+			},
+			componentName: "c1",
+			wantInstalled: []InstalledComponentVersion{
+				{Name: "c2", Version: "v1"},
+			},
+		},
+		{
+			name: "by name and version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+				{Name: "c1", Version: "v2"},
+			},
+			componentName: "c1",
+			version:       "v2",
+			wantInstalled: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+			},
+		},
+		{
+			name: "ambiguous",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+				{Name: "c1", Version: "v2"},
+			},
+			componentName: "c1",
+			wantErr:       errors.New("multiple versions of component c1 installed, version has to be specified"),
+		},
+		{
+			name: "missing",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+			},
+			componentName: "c1",
+			version:       "v2",
+			wantErr:       errors.New("no such component installed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			e, err := Create(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, ic := range tt.installed {
+				util.EnsureDirectory(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), ic.Name, ic.Version, util.DefaultComponentMountsSubdirectory))
+				e.Installed = append(e.Installed, ic)
+			}
+			err = e.Uninstall(tt.componentName, tt.version)
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			a.NoError(err)
+			a.Equal(tt.wantInstalled, e.Installed)
+			for _, ic := range tt.wantInstalled {
+				a.DirExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), ic.Name, ic.Version))
+			}
+			a.NoDirExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), tt.componentName, tt.version))
+			saved, err := Get(e.Uuid)
+			a.NoError(err)
+			if a.Len(saved.Installed, len(tt.wantInstalled)) {
+				for i, ic := range tt.wantInstalled {
+					a.Equal(ic.Name, saved.Installed[i].Name)
+					a.Equal(ic.Version, saved.Installed[i].Version)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvironment_Upgrade(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "env-upgrade")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+
+	tests := []struct {
+		name          string
+		newComponent  InstalledComponentVersion
+		migrateMounts bool
+		force         bool
+		wantErr       error
+	}{
+		{
+			name:          "with mounts migration",
+			newComponent:  InstalledComponentVersion{Name: "c1", Type: "local", Version: "v2", Image: "sh"},
+			migrateMounts: true,
+		},
+		{
+			name:          "without mounts migration",
+			newComponent:  InstalledComponentVersion{Name: "c1", Type: "local", Version: "v2", Image: "sh"},
+			migrateMounts: false,
+		},
+		{
+			name:         "same version",
+			newComponent: InstalledComponentVersion{Name: "c1", Version: "v1"},
+			wantErr:      errors.New("this version of component is already installed in environment"),
+		},
+		{
+			name:         "not installed",
+			newComponent: InstalledComponentVersion{Name: "c2", Version: "v2"},
+			wantErr:      errors.New("no such component installed"),
+		},
+		{
+			name:         "older version",
+			newComponent: InstalledComponentVersion{Name: "c1", Type: "local", Version: "v0.9", Image: "sh"},
+			wantErr:      errors.New("version v0.9 is not newer than installed version v1, upgrade has to be forced"),
+		},
+		{
+			name:         "older version forced",
+			newComponent: InstalledComponentVersion{Name: "c1", Type: "local", Version: "v0.9", Image: "sh"},
+			force:        true,
+		},
+		{
+			name:         "not semantic version",
+			newComponent: InstalledComponentVersion{Name: "c1", Type: "local", Version: "dev", Image: "sh"},
+		},
+		{
+			name:         "download failed",
+			newComponent: InstalledComponentVersion{Name: "c1", Type: "local", Version: "v2", Image: "not-existing-binary"},
+			wantErr:      errors.New("executable not-existing-binary of component c1 not found: exec: \"not-existing-binary\": executable file not found in $PATH"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			e, err := Create(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = e.Install(context.Background(), InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "c1", Type: "local", Version: "v1", Image: "sh"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldMountsDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", "v1", util.DefaultComponentMountsSubdirectory)
+			err = ioutil.WriteFile(path.Join(oldMountsDirectory, "state"), []byte("content"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			oldRunsDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", "v1", util.DefaultComponentRunsSubdirectory, "run1")
+			err = os.MkdirAll(oldRunsDirectory, 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.newComponent.EnvironmentRef = e.Uuid
+			err = e.Upgrade(context.Background(), tt.newComponent, tt.migrateMounts, tt.force)
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				a.DirExists(oldRunsDirectory)
+				if tt.newComponent.Name == "c1" && tt.newComponent.Version != "v1" {
+					a.NoDirExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", tt.newComponent.Version))
+				}
+				return
+			}
+			a.NoError(err)
+			a.Equal([]InstalledComponentVersion{tt.newComponent}, e.Installed)
+			a.NoDirExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", "v1"))
+			newMountsDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", tt.newComponent.Version, util.DefaultComponentMountsSubdirectory)
+			a.DirExists(newMountsDirectory)
+			a.DirExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", tt.newComponent.Version, util.DefaultComponentRunsSubdirectory, "run1"))
+			if tt.migrateMounts {
+				a.FileExists(path.Join(newMountsDirectory, "state"))
+			} else {
+				a.NoFileExists(path.Join(newMountsDirectory, "state"))
+			}
+		})
+	}
+}
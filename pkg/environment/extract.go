@@ -0,0 +1,140 @@
+package environment
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/mholt/archiver/v3"
+)
+
+var (
+	//maxImportSize is limit of total size of files extracted from imported archive
+	maxImportSize int64 = 2 << 30
+	//maxImportEntries is limit of number of files and directories in imported archive
+	maxImportEntries = 20000
+)
+
+//extractEnvironmentArchive extracts archive into staging directory and returns uuid of environment it contains.
+//Every entry has to be regular file or directory placed inside single top-level directory named with environment
+//uuid, total size and number of entries are limited.
+func extractEnvironmentArchive(srcFile, staging string) (uuid.UUID, error) {
+	var (
+		envUuid    uuid.UUID
+		entries    int
+		size       int64
+		extractErr error
+	)
+	err := archiver.Walk(srcFile, func(f archiver.File) error {
+		extractErr = func() error {
+			entries++
+			if entries > maxImportEntries {
+				return fmt.Errorf("archive has more than %d entries", maxImportEntries)
+			}
+			name, err := entryName(f)
+			if err != nil {
+				return err
+			}
+			rel, err := cleanEntryName(name)
+			if err != nil {
+				return err
+			}
+			top := strings.SplitN(rel, "/", 2)[0]
+			id, err := uuid.Parse(top)
+			if err != nil || id.String() != top {
+				return fmt.Errorf("archive entry %s is not inside environment directory", name)
+			}
+			if envUuid == uuid.Nil {
+				envUuid = id
+			} else if id != envUuid {
+				return fmt.Errorf("archive contains more than one environment (%s and %s)", envUuid.String(), top)
+			}
+
+			target := filepath.Join(staging, filepath.FromSlash(rel))
+			switch {
+			case f.IsDir():
+				return os.MkdirAll(target, 0755)
+			case f.Mode().IsRegular():
+				if size+f.Size() > maxImportSize {
+					return fmt.Errorf("archive content is larger than %d bytes", maxImportSize)
+				}
+				err = os.MkdirAll(filepath.Dir(target), 0755)
+				if err != nil {
+					return err
+				}
+				// owner can always read and write, group and others never write
+				out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, f.Mode().Perm()&^0022|0600)
+				if os.IsExist(err) {
+					return fmt.Errorf("archive entry %s is duplicated", name)
+				}
+				if err != nil {
+					return err
+				}
+				// declared size cannot be trusted so content is limited too
+				n, err := io.Copy(out, io.LimitReader(f, maxImportSize-size+1))
+				if err2 := out.Close(); err == nil {
+					err = err2
+				}
+				if err != nil {
+					return err
+				}
+				size += n
+				if size > maxImportSize {
+					return fmt.Errorf("archive content is larger than %d bytes", maxImportSize)
+				}
+				return nil
+			default:
+				return fmt.Errorf("archive entry %s is not regular file or directory", name)
+			}
+		}()
+		if extractErr != nil {
+			return archiver.ErrStopWalk
+		}
+		return nil
+	})
+	if extractErr != nil {
+		return uuid.Nil, extractErr
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if envUuid == uuid.Nil {
+		return uuid.Nil, errors.New("archive does not contain environment directory")
+	}
+	return envUuid, nil
+}
+
+//entryName returns full path of archive entry, archiver.File.Name returns base name only
+func entryName(f archiver.File) (string, error) {
+	switch h := f.Header.(type) {
+	case zip.FileHeader:
+		return h.Name, nil
+	case *tar.Header:
+		if h.Typeflag != tar.TypeReg && h.Typeflag != tar.TypeRegA && h.Typeflag != tar.TypeDir {
+			return "", fmt.Errorf("archive entry %s is not regular file or directory", h.Name)
+		}
+		return h.Name, nil
+	default:
+		return "", fmt.Errorf("unsupported archive entry %s", f.Name())
+	}
+}
+
+//cleanEntryName returns normalized relative path of archive entry, paths leading outside of extraction directory
+//are refused
+func cleanEntryName(name string) (string, error) {
+	if name == "" || strings.Contains(name, `\`) || path.IsAbs(name) {
+		return "", fmt.Errorf("archive entry %q has incorrect path", name)
+	}
+	clean := path.Clean(name)
+	if clean == "." || clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", fmt.Errorf("archive entry %q has incorrect path", name)
+	}
+	return clean, nil
+}
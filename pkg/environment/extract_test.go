@@ -0,0 +1,364 @@
+package environment
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type zipEntry struct {
+	name    string
+	content string
+	mode    os.FileMode
+}
+
+func writeZip(t *testing.T, file string, entries []zipEntry) {
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	for _, e := range entries {
+		h := &zip.FileHeader{Name: e.name, Method: zip.Deflate}
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		h.SetMode(mode)
+		ew, err := w.CreateHeader(h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = ew.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImport_Archive(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "import-archive")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	defer func(size int64, entries int) {
+		maxImportSize, maxImportEntries = size, entries
+	}(maxImportSize, maxImportEntries)
+	maxImportSize, maxImportEntries = 1000, 10
+
+	id, other := uuid.New(), uuid.New()
+	config := func(id, ref uuid.UUID) string {
+		return fmt.Sprintf("name: imported\nuuid: %s\ninstalled:\n- environment_ref: %s\n  name: c1\n  type: local\n  version: v1\n  image: sh\n", id, ref)
+	}
+	valid := zipEntry{name: id.String() + "/config.yaml", content: config(id, id)}
+
+	tests := []struct {
+		name    string
+		entries []zipEntry
+		wantErr error
+	}{
+		{
+			name: "valid",
+			entries: []zipEntry{
+				{name: id.String() + "/", mode: os.ModeDir | 0755},
+				valid,
+				{name: id.String() + "/shared/vms_rsa", content: "key", mode: 0600},
+				{name: id.String() + "/c1/v1/mounts/run.sh", content: "#!/bin/sh", mode: 0777},
+			},
+		},
+		{
+			name:    "path traversal",
+			entries: []zipEntry{valid, {name: "../evil", content: "x"}},
+			wantErr: errors.New(`archive entry "../evil" has incorrect path`),
+		},
+		{
+			name:    "path traversal inside environment directory",
+			entries: []zipEntry{valid, {name: id.String() + "/../../evil", content: "x"}},
+			wantErr: fmt.Errorf(`archive entry "%s/../../evil" has incorrect path`, id),
+		},
+		{
+			name:    "absolute path",
+			entries: []zipEntry{valid, {name: "/tmp/evil", content: "x"}},
+			wantErr: errors.New(`archive entry "/tmp/evil" has incorrect path`),
+		},
+		{
+			name:    "backslash",
+			entries: []zipEntry{valid, {name: id.String() + `\..\..\evil`, content: "x"}},
+			wantErr: fmt.Errorf(`archive entry "%s\\..\\..\\evil" has incorrect path`, id),
+		},
+		{
+			name:    "symlink",
+			entries: []zipEntry{valid, {name: id.String() + "/shared/link", content: "/etc/passwd", mode: os.ModeSymlink | 0777}},
+			wantErr: fmt.Errorf("archive entry %s/shared/link is not regular file or directory", id),
+		},
+		{
+			name:    "entry outside environment directory",
+			entries: []zipEntry{valid, {name: "shared/file", content: "x"}},
+			wantErr: errors.New("archive entry shared/file is not inside environment directory"),
+		},
+		{
+			name:    "other environment",
+			entries: []zipEntry{valid, {name: other.String() + "/config.yaml", content: config(other, other)}},
+			wantErr: fmt.Errorf("archive contains more than one environment (%s and %s)", id, other),
+		},
+		{
+			name:    "duplicated entry",
+			entries: []zipEntry{valid, valid},
+			wantErr: fmt.Errorf("archive entry %s/config.yaml is duplicated", id),
+		},
+		{
+			name:    "config of other environment",
+			entries: []zipEntry{{name: id.String() + "/config.yaml", content: config(other, other)}},
+			wantErr: fmt.Errorf("environment id %s in the config does not match directory %s in archive", other, id),
+		},
+		{
+			name:    "component of other environment",
+			entries: []zipEntry{{name: id.String() + "/config.yaml", content: config(id, other)}},
+			wantErr: fmt.Errorf("installed component c1 refers to other environment %s", other),
+		},
+		{
+			name:    "too large",
+			entries: []zipEntry{valid, {name: id.String() + "/shared/big", content: string(make([]byte, 1000))}},
+			wantErr: errors.New("archive content is larger than 1000 bytes"),
+		},
+		{
+			name: "too many entries",
+			entries: func() []zipEntry {
+				entries := []zipEntry{valid}
+				for i := 0; i < 10; i++ {
+					entries = append(entries, zipEntry{name: fmt.Sprintf("%s/shared/%d", id, i)})
+				}
+				return entries
+			}(),
+			wantErr: errors.New("archive has more than 10 entries"),
+		},
+		{
+			name:    "empty",
+			wantErr: errors.New("archive does not contain environment directory"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			_ = os.RemoveAll(path.Join(util.UsedEnvironmentDirectory, id.String()))
+			file := path.Join(util.UsedConfigurationDirectory, "crafted.zip")
+			writeZip(t, file, tt.entries)
+
+			got, err := Import(context.Background(), file, ImportOptions{})
+			items, err2 := ioutil.ReadDir(util.UsedTempDirectory)
+			a.NoError(err2)
+			a.Empty(items, "staging directory was not removed")
+			a.NoFileExists(path.Join(util.UsedConfigurationDirectory, "evil"))
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				a.NoDirExists(path.Join(util.UsedEnvironmentDirectory, id.String()))
+				a.NoDirExists(path.Join(util.UsedEnvironmentDirectory, other.String()))
+				return
+			}
+			a.NoError(err)
+			a.Equal(id, got)
+			e, err := Get(id)
+			a.NoError(err)
+			a.Equal("imported", e.Name)
+			info, err := os.Stat(path.Join(util.UsedEnvironmentDirectory, id.String(), "shared", "vms_rsa"))
+			a.NoError(err)
+			a.Equal(os.FileMode(0600), info.Mode().Perm())
+			info, err = os.Stat(path.Join(util.UsedEnvironmentDirectory, id.String(), "c1", "v1", "mounts", "run.sh"))
+			a.NoError(err)
+			a.Equal(os.FileMode(0755), info.Mode().Perm())
+		})
+	}
+}
+
+func TestImport_Existing(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "import-existing")
+	util.UsedSnapshotsDirectory = path.Join(util.UsedConfigurationDirectory, util.DefaultSnapshotsSubdirectory)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+
+	id := uuid.New()
+	file := path.Join(util.UsedConfigurationDirectory, "existing.zip")
+	writeZip(t, file, []zipEntry{
+		{name: id.String() + "/config.yaml", content: fmt.Sprintf("name: imported\nuuid: %s\ninstalled:\n- environment_ref: %s\n  name: c1\n  type: local\n  version: v1\n  image: sh\n", id, id)},
+		{name: id.String() + "/shared/file", content: "imported"},
+	})
+	_, err := Import(context.Background(), file, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path.Join(util.UsedEnvironmentDirectory, id.String(), "shared", "file"), []byte("changed"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		options  ImportOptions
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:    "refused",
+			wantErr: fmt.Errorf("environment with id %s already exists", id),
+		},
+		{
+			name:    "as new and overwrite",
+			options: ImportOptions{AsNew: true, Overwrite: true},
+			wantErr: errors.New("environment cannot be imported as new and overwrite existing one at the same time"),
+		},
+		{
+			name:     "as new with taken name",
+			options:  ImportOptions{AsNew: true},
+			wantName: "imported-imported",
+		},
+		{
+			name:     "as new with taken name again",
+			options:  ImportOptions{AsNew: true},
+			wantName: "imported-imported-2",
+		},
+		{
+			name:    "as new with taken explicit name",
+			options: ImportOptions{AsNew: true, Name: "imported"},
+			wantErr: fmt.Errorf("environment with name imported already exists (%s)", id),
+		},
+		{
+			name:     "as new",
+			options:  ImportOptions{AsNew: true, Name: "copy"},
+			wantName: "copy",
+		},
+		{
+			name:    "overwrite",
+			options: ImportOptions{Overwrite: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := Import(context.Background(), file, tt.options)
+			items, err2 := ioutil.ReadDir(util.UsedTempDirectory)
+			a.NoError(err2)
+			a.Empty(items, "staging directory was not removed")
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				b, err := ioutil.ReadFile(path.Join(util.UsedEnvironmentDirectory, id.String(), "shared", "file"))
+				a.NoError(err)
+				a.Equal("changed", string(b))
+				return
+			}
+			a.NoError(err)
+			e, err := Get(got)
+			a.NoError(err)
+			b, err := ioutil.ReadFile(path.Join(util.UsedEnvironmentDirectory, got.String(), "shared", "file"))
+			a.NoError(err)
+			a.Equal("imported", string(b))
+			if tt.options.AsNew {
+				a.NotEqual(id, got)
+				a.Equal(tt.wantName, e.Name)
+				a.Equal(got, e.Installed[0].EnvironmentRef)
+				a.DirExists(path.Join(util.UsedEnvironmentDirectory, id.String()))
+				return
+			}
+			a.Equal(id, got)
+			a.Equal("imported", e.Name)
+			snapshots, err := e.Snapshots()
+			a.NoError(err)
+			if a.Len(snapshots, 1) {
+				a.Equal("before overwrite by import", snapshots[0].Description)
+				a.Equal(id, snapshots[0].Environment)
+			}
+		})
+	}
+}
+
+func TestImport_Download(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "import-download")
+	util.UsedSnapshotsDirectory = path.Join(util.UsedConfigurationDirectory, util.DefaultSnapshotsSubdirectory)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	defer delete(runtimes, "pulling")
+
+	id := uuid.New()
+	file := path.Join(util.UsedConfigurationDirectory, "download.zip")
+	writeZip(t, file, []zipEntry{
+		{name: id.String() + "/config.yaml", content: fmt.Sprintf("name: download\nuuid: %s\ninstalled:\n- environment_ref: %s\n  name: c1\n  type: pulling\n  version: v1\n  image: org/c1:v1\n", id, id)},
+	})
+
+	tests := []struct {
+		name    string
+		runtime pullingRuntime
+		options ImportOptions
+		wantErr error
+	}{
+		{
+			name:    "download failed",
+			runtime: pullingRuntime{err: errors.New("pull failed")},
+			wantErr: errors.New("pull failed"),
+		},
+		{
+			name:    "fresh",
+			runtime: pullingRuntime{pinned: "sha256:a"},
+		},
+		{
+			name:    "as new",
+			runtime: pullingRuntime{pinned: "sha256:b"},
+			options: ImportOptions{AsNew: true, Name: "copy"},
+		},
+		{
+			name:    "overwrite with download failed",
+			runtime: pullingRuntime{err: errors.New("pull failed")},
+			options: ImportOptions{Overwrite: true},
+			wantErr: errors.New("pull failed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			runtimes["pulling"] = tt.runtime
+			existed, err := IsExisting(id)
+			a.NoError(err)
+
+			got, err := Import(context.Background(), file, tt.options)
+			items, err2 := ioutil.ReadDir(util.UsedTempDirectory)
+			a.NoError(err2)
+			a.Empty(items, "staging directory was not removed")
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				if !existed {
+					a.NoDirExists(path.Join(util.UsedEnvironmentDirectory, id.String()))
+					return
+				}
+				e, err := Get(id)
+				a.NoError(err)
+				a.Equal("sha256:a", e.Installed[0].Digest, "previous environment was not restored")
+				return
+			}
+			a.NoError(err)
+			e, err := Get(got)
+			a.NoError(err)
+			if a.Len(e.Installed, 1) {
+				a.Equal(tt.runtime.pinned, e.Installed[0].Digest)
+			}
+			logs, err := filepath.Glob(path.Join(util.UsedEnvironmentDirectory, got.String(), "c1", "v1", util.DefaultComponentRunsSubdirectory, "*.log"))
+			a.NoError(err)
+			a.Len(logs, 1)
+		})
+	}
+}
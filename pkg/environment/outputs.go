@@ -0,0 +1,87 @@
+package environment
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"gopkg.in/yaml.v2"
+)
+
+//Outputs holds values of outputs of single component by output name
+type Outputs map[string]interface{}
+
+//ReadOutputs reads outputs file written by component to one of its own mounts (shared directory is not checked)
+//and returns values of outputs declared by component. Nil is returned if component declares no outputs or if
+//outputs file is missing, which is the case for commands not producing outputs.
+func (cv *InstalledComponentVersion) ReadOutputs() (Outputs, error) {
+	if len(cv.Outputs) == 0 {
+		return nil, nil
+	}
+	mounts := cv.mounts()
+	targets := append([]string{}, cv.Mounts...)
+	sort.Strings(targets)
+	for _, t := range targets {
+		p := path.Join(mounts[t], util.DefaultComponentOutputsFileName)
+		content, err := ioutil.ReadFile(p)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		logger.Debug().Msgf("will try to read outputs from file %s", p)
+		values := make(Outputs)
+		err = yaml.Unmarshal(content, &values)
+		if err != nil {
+			return nil, fmt.Errorf("incorrect outputs file %s: %w", p, err)
+		}
+		result := make(Outputs)
+		for _, o := range cv.Outputs {
+			v, ok := values[o.Name]
+			if !ok {
+				logger.Warn().Msgf("component %s did not provide declared output %s", cv.Name, o.Name)
+				continue
+			}
+			result[o.Name] = v
+			delete(values, o.Name)
+		}
+		for k := range values {
+			logger.Warn().Msgf("component %s provided undeclared output %s which is ignored", cv.Name, k)
+		}
+		return result, nil
+	}
+	logger.Debug().Msgf("no %s file found in mounts of component %s", util.DefaultComponentOutputsFileName, cv.Name)
+	return nil, nil
+}
+
+//CollectOutputs reads outputs of installed component and stores them in environment replacing previously
+//collected outputs of the component
+func (e *Environment) CollectOutputs(cv *InstalledComponentVersion) error {
+	values, err := cv.ReadOutputs()
+	if err != nil {
+		return err
+	}
+	if values == nil {
+		return nil
+	}
+	if e.Outputs == nil {
+		e.Outputs = make(map[string]Outputs)
+	}
+	e.Outputs[cv.Name] = values
+	return e.Save()
+}
+
+//GetOutputs returns collected outputs of named component
+func (e *Environment) GetOutputs(name string) (Outputs, error) {
+	values, ok := e.Outputs[name]
+	if !ok {
+		return nil, fmt.Errorf("no outputs collected for component %s", name)
+	}
+	return values, nil
+}
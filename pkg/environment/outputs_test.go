@@ -0,0 +1,90 @@
+package environment
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironment_CollectOutputs(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "outputs")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+
+	tests := []struct {
+		name        string
+		declared    []InstalledComponentOutput
+		file        string
+		wantOutputs map[string]Outputs
+		wantErr     error
+	}{
+		{
+			name:     "declared outputs collected",
+			declared: []InstalledComponentOutput{{Name: "ip"}, {Name: "names"}, {Name: "missing"}},
+			file:     "ip: 10.0.0.1\nnames:\n- vm1\n- vm2\nundeclared: value\n",
+			wantOutputs: map[string]Outputs{
+				"c1": {"ip": "10.0.0.1", "names": []interface{}{"vm1", "vm2"}},
+			},
+		},
+		{
+			name:     "no outputs file",
+			declared: []InstalledComponentOutput{{Name: "ip"}},
+		},
+		{
+			name: "no declared outputs",
+			file: "ip: 10.0.0.1\n",
+		},
+		{
+			name:     "incorrect outputs file",
+			declared: []InstalledComponentOutput{{Name: "ip"}},
+			file:     "- ip",
+			wantErr:  errors.New("incorrect outputs file"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			e, err := Create(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cv := &InstalledComponentVersion{
+				EnvironmentRef: e.Uuid,
+				Name:           "c1",
+				Version:        "v1",
+				Mounts:         []string{"/data"},
+				Outputs:        tt.declared,
+			}
+			e.Installed = append(e.Installed, *cv)
+			if tt.file != "" {
+				dir := cv.mounts()["/data"]
+				util.EnsureDirectory(dir)
+				err = ioutil.WriteFile(path.Join(dir, util.DefaultComponentOutputsFileName), []byte(tt.file), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = e.CollectOutputs(cv)
+			if tt.wantErr != nil {
+				if a.Error(err) {
+					a.Contains(err.Error(), tt.wantErr.Error())
+				}
+				return
+			}
+			a.NoError(err)
+			saved, err := Get(e.Uuid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.Equal(tt.wantOutputs, saved.Outputs)
+		})
+	}
+}
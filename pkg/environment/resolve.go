@@ -0,0 +1,93 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+
+	"github.com/google/uuid"
+)
+
+//Resolve returns environment referenced by its full id, unique id prefix or name. Exact name match takes precedence
+//over id prefix. If reference matches more than one environment error listing all candidates is returned.
+func Resolve(ref string) (*Environment, error) {
+	logger.Debug().Msgf("will try to resolve environment %s", ref)
+	if ref == "" {
+		return nil, errors.New("environment id or name cannot be empty")
+	}
+	if id, err := uuid.Parse(ref); err == nil {
+		e, err := Get(id)
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("environment %s not found", ref)
+		}
+		return e, err
+	}
+	environments, err := GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var byName, byPrefix []*Environment
+	for _, e := range environments {
+		if e.Name == ref {
+			byName = append(byName, e)
+		}
+		if strings.HasPrefix(e.Uuid.String(), strings.ToLower(ref)) {
+			byPrefix = append(byPrefix, e)
+		}
+	}
+	found := byName
+	if len(found) == 0 {
+		found = byPrefix
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("environment %s not found", ref)
+	case 1:
+		logger.Debug().Msgf("environment %s resolved to %s", ref, found[0].Uuid.String())
+		return found[0], nil
+	default:
+		var candidates []string
+		for _, e := range found {
+			candidates = append(candidates, fmt.Sprintf("%s (%s)", e.Name, e.Uuid.String()))
+		}
+		return nil, fmt.Errorf("environment %s is ambiguous, it matches: %s", ref, strings.Join(candidates, ", "))
+	}
+}
+
+//checkNameUnique returns error if environment other than one with provided id already has provided name
+func checkNameUnique(name string, id uuid.UUID) error {
+	environments, err := GetAll()
+	if err != nil {
+		return err
+	}
+	for _, e := range environments {
+		if e.Name == name && e.Uuid != id {
+			return fmt.Errorf("environment with name %s already exists (%s)", name, e.Uuid.String())
+		}
+	}
+	return nil
+}
+
+//freeName returns name if no environment has it yet, otherwise "<name>-<suffix>" or "<name>-<suffix>-<n>" with
+//the lowest n making it unique
+func freeName(name, suffix string) (string, error) {
+	environments, err := GetAll()
+	if err != nil {
+		return "", err
+	}
+	taken := make(map[string]bool)
+	for _, e := range environments {
+		taken[e.Name] = true
+	}
+	if !taken[name] {
+		return name, nil
+	}
+	candidate := fmt.Sprintf("%s-%s", name, suffix)
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s-%s-%d", name, suffix, n)
+	}
+	return candidate, nil
+}
@@ -0,0 +1,129 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolve(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "resolve")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	unique, err := Create("unique")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// names are unique since Create checks it, but environments created before may share them
+	first, err := create("twin", uuid.MustParse("1f6a0c16-0000-4000-8000-000000000001"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := create("twin", uuid.MustParse("1f6a0c16-0000-4000-8000-000000000002"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	named, err := create("1f6a0c16", uuid.MustParse("9b1c4f2e-0000-4000-8000-000000000003"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	unknown := uuid.New()
+
+	tests := []struct {
+		name    string
+		ref     string
+		want    uuid.UUID
+		wantErr error
+	}{
+		{
+			name: "by id",
+			ref:  first.Uuid.String(),
+			want: first.Uuid,
+		},
+		{
+			name: "by name",
+			ref:  "unique",
+			want: unique.Uuid,
+		},
+		{
+			name: "by unique id prefix",
+			ref:  "9b1c",
+			want: named.Uuid,
+		},
+		{
+			name: "by upper case id prefix",
+			ref:  "9B1C4F",
+			want: named.Uuid,
+		},
+		{
+			name: "name takes precedence over id prefix",
+			ref:  "1f6a0c16",
+			want: named.Uuid,
+		},
+		{
+			name:    "ambiguous id prefix",
+			ref:     "1f6a0c16-0000",
+			wantErr: fmt.Errorf("environment 1f6a0c16-0000 is ambiguous, it matches: twin (%s), twin (%s)", first.Uuid, second.Uuid),
+		},
+		{
+			name:    "empty",
+			ref:     "",
+			wantErr: errors.New("environment id or name cannot be empty"),
+		},
+		{
+			name:    "unknown id",
+			ref:     unknown.String(),
+			wantErr: fmt.Errorf("environment %s not found", unknown),
+		},
+		{
+			name:    "unknown name",
+			ref:     "missing",
+			wantErr: errors.New("environment missing not found"),
+		},
+		{
+			name:    "ambiguous name",
+			ref:     "twin",
+			wantErr: fmt.Errorf("environment twin is ambiguous, it matches: twin (%s), twin (%s)", first.Uuid, second.Uuid),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := Resolve(tt.ref)
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			if a.NoError(err) {
+				a.Equal(tt.want, got.Uuid)
+			}
+		})
+	}
+}
+
+func TestCreate_UniqueName(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "unique-name")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	first, err := Create("first")
+	a.NoError(err)
+	second, err := Create("second")
+	a.NoError(err)
+
+	_, err = Create("first")
+	a.EqualError(err, fmt.Sprintf("environment with name first already exists (%s)", first.Uuid))
+	a.EqualError(second.Rename("first"), fmt.Sprintf("environment with name first already exists (%s)", first.Uuid))
+	a.NoError(second.Rename("second"))
+	_, err = second.Clone("first")
+	a.EqualError(err, fmt.Sprintf("environment with name first already exists (%s)", first.Uuid))
+}
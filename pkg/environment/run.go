@@ -0,0 +1,190 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	runRecordFileName = "run.yaml"
+	runStdoutFileName = "stdout.log"
+	runStderrFileName = "stderr.log"
+	runIdFormat       = "20060102-150405.000MST"
+)
+
+//RunRecord holds information about single run of installed component command
+type RunRecord struct {
+	Id        string    `yaml:"id"`
+	Component string    `yaml:"component"`
+	Version   string    `yaml:"version"`
+	Name      string    `yaml:"name"`
+	Image     string    `yaml:"image"`
+	Digest    string    `yaml:"digest,omitempty"`
+	Command   string    `yaml:"command"`
+	Args      []string  `yaml:"args"`
+	Started   time.Time `yaml:"started"`
+	Finished  time.Time `yaml:"finished"`
+	ExitCode  int64     `yaml:"exit_code"`
+	Error     string    `yaml:"error,omitempty"`
+
+	directory string
+	stdout    *os.File
+	stderr    *os.File
+}
+
+//newRunRecord creates directory for new run of command in runs subdirectory of component version and opens
+//files to which output of the run is written
+func newRunRecord(cv *InstalledComponentVersion, command string) (*RunRecord, error) {
+	r := &RunRecord{
+		Id:        time.Now().Format(runIdFormat),
+		Component: cv.Name,
+		Version:   cv.Version,
+		Name:      command,
+		Image:     cv.Image,
+		Digest:    cv.Digest,
+		ExitCode:  -1,
+	}
+	r.directory = path.Join(cv.runsDirectory(), r.Id)
+	util.EnsureDirectory(r.directory)
+	var err error
+	r.stdout, err = os.Create(path.Join(r.directory, runStdoutFileName))
+	if err != nil {
+		return nil, err
+	}
+	r.stderr, err = os.Create(path.Join(r.directory, runStderrFileName))
+	if err != nil {
+		_ = r.stdout.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+//Stdout returns writer passing run standard output both to os.Stdout and to run record file
+func (r *RunRecord) Stdout() io.Writer {
+	if r == nil || r.stdout == nil {
+		return os.Stdout
+	}
+	return io.MultiWriter(os.Stdout, r.stdout)
+}
+
+//Stderr returns writer passing run standard error both to os.Stderr and to run record file
+func (r *RunRecord) Stderr() io.Writer {
+	if r == nil || r.stderr == nil {
+		return os.Stderr
+	}
+	return io.MultiWriter(os.Stderr, r.stderr)
+}
+
+//Close closes output files and persists run record metadata
+func (r *RunRecord) Close() error {
+	if r.stdout != nil {
+		_ = r.stdout.Close()
+	}
+	if r.stderr != nil {
+		_ = r.stderr.Close()
+	}
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	p := path.Join(r.directory, runRecordFileName)
+	logger.Debug().Msgf("will try to write run record to file %s", p)
+	return ioutil.WriteFile(p, data, 0644)
+}
+
+//Output returns standard output and standard error persisted for run
+func (r *RunRecord) Output() (string, string, error) {
+	stdout, err := ioutil.ReadFile(path.Join(r.directory, runStdoutFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", err
+	}
+	stderr, err := ioutil.ReadFile(path.Join(r.directory, runStderrFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", err
+	}
+	return string(stdout), string(stderr), nil
+}
+
+//runsDirectory returns path to runs subdirectory of installed component version
+func (cv *InstalledComponentVersion) runsDirectory() string {
+	return path.Join(
+		util.UsedEnvironmentDirectory,
+		cv.EnvironmentRef.String(),
+		cv.Name,
+		cv.Version,
+		util.DefaultComponentRunsSubdirectory,
+	)
+}
+
+//GetRuns returns all persisted run records of installed component version ordered from the oldest one
+func (cv *InstalledComponentVersion) GetRuns() ([]*RunRecord, error) {
+	items, err := ioutil.ReadDir(cv.runsDirectory())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var runs []*RunRecord
+	for _, i := range items {
+		if !i.IsDir() {
+			continue
+		}
+		r, err := readRunRecord(path.Join(cv.runsDirectory(), i.Name()))
+		if err != nil {
+			logger.Warn().Err(err).Msgf("directory %s does not seam like run record", i.Name())
+			continue
+		}
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].Started.Before(runs[j].Started)
+	})
+	return runs, nil
+}
+
+//GetRun returns run record with provided id or the latest one if id is empty
+func (cv *InstalledComponentVersion) GetRun(id string) (*RunRecord, error) {
+	runs, err := cv.GetRuns()
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, errors.New("no runs recorded for this component")
+	}
+	if id == "" {
+		return runs[len(runs)-1], nil
+	}
+	for _, r := range runs {
+		if r.Id == id {
+			return r, nil
+		}
+	}
+	return nil, fmt.Errorf("run %s not found", id)
+}
+
+//readRunRecord loads RunRecord from run.yaml file in provided directory
+func readRunRecord(directory string) (*RunRecord, error) {
+	data, err := ioutil.ReadFile(path.Join(directory, runRecordFileName))
+	if err != nil {
+		return nil, err
+	}
+	r := &RunRecord{}
+	err = yaml.Unmarshal(data, r)
+	if err != nil {
+		return nil, err
+	}
+	r.directory = directory
+	return r, nil
+}
@@ -0,0 +1,78 @@
+package environment
+
+import (
+	"io"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstalledComponentVersion_GetRuns(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "runs")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	cv := &InstalledComponentVersion{
+		EnvironmentRef: uuid.MustParse("3e5b7269-1b3d-4003-9454-9f472857633a"),
+		Name:           "c1",
+		Version:        "v1",
+		Image:          "i1",
+	}
+
+	runs, err := cv.GetRuns()
+	a.NoError(err)
+	a.Empty(runs)
+	_, err = cv.GetRun("")
+	a.EqualError(err, "no runs recorded for this component")
+
+	var ids []string
+	for i, command := range []string{"init", "apply"} {
+		r, err := newRunRecord(cv, command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = io.WriteString(r.stdout, command+" stdout")
+		_, _ = io.WriteString(r.stderr, command+" stderr")
+		r.Command = "terraform"
+		r.Args = []string{command}
+		r.Started = time.Now().Add(time.Duration(i) * time.Minute)
+		r.Finished = r.Started.Add(time.Second)
+		r.ExitCode = int64(i)
+		a.NoError(r.Close())
+		a.FileExists(path.Join(cv.runsDirectory(), r.Id, runRecordFileName))
+		ids = append(ids, r.Id)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	runs, err = cv.GetRuns()
+	a.NoError(err)
+	if a.Len(runs, 2) {
+		a.Equal("init", runs[0].Name)
+		a.Equal("apply", runs[1].Name)
+		a.Equal(int64(1), runs[1].ExitCode)
+		a.Equal([]string{"apply"}, runs[1].Args)
+	}
+
+	latest, err := cv.GetRun("")
+	a.NoError(err)
+	a.Equal(ids[1], latest.Id)
+	stdout, stderr, err := latest.Output()
+	a.NoError(err)
+	a.Equal("apply stdout", stdout)
+	a.Equal("apply stderr", stderr)
+
+	first, err := cv.GetRun(ids[0])
+	a.NoError(err)
+	a.Equal("init", first.Name)
+
+	_, err = cv.GetRun("unknown")
+	a.EqualError(err, "run unknown not found")
+}
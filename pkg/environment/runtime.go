@@ -0,0 +1,127 @@
+package environment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+//Runtime executes commands of installed components of single component type
+type Runtime interface {
+	//Download prepares everything needed to run commands of component version, e.g. pulls docker image
+	Download(ctx context.Context, cv *InstalledComponentVersion) error
+	//Run executes job and waits for it to finish. Non-zero exit code of job is returned as *ExitError
+	//and cancellation of ctx stops job.
+	Run(ctx context.Context, job Job) error
+}
+
+//ImageVerifier is implemented by runtimes which pin component images by content digest
+type ImageVerifier interface {
+	//VerifyImage compares digest image of component version was pinned to with digests its tag points to now
+	VerifyImage(ctx context.Context, cv *InstalledComponentVersion) ImageVerification
+}
+
+const (
+	VerificationOk        = "ok"         // image tag still points to pinned digest
+	VerificationDrift     = "drift"      // image tag points to other content than pinned one
+	VerificationNotPinned = "not pinned" // no digest was recorded at install
+	VerificationUnknown   = "unknown"    // current digest of image tag cannot be checked
+)
+
+//ImageVerification struct contains result of ImageVerifier check. Empty digest means it could not be resolved.
+type ImageVerification struct {
+	Component string
+	Version   string
+	Image     string
+	Pinned    string   // digest recorded at install
+	Local     string   // digest image tag points to in local image store
+	Registry  string   // digest image tag points to in registry
+	Errors    []string // reasons of digests not being resolved
+}
+
+//Status returns one of Verification* values
+func (v ImageVerification) Status() string {
+	switch {
+	case v.Pinned == "":
+		return VerificationNotPinned
+	case v.Local != "" && v.Local != v.Pinned, v.Registry != "" && v.Registry != v.Pinned:
+		return VerificationDrift
+	case v.Local == "" && v.Registry == "":
+		return VerificationUnknown
+	default:
+		return VerificationOk
+	}
+}
+
+//Job describes single execution of installed component command prepared for Runtime
+type Job struct {
+	Image                string            // docker image or host executable of component
+	Digest               string            // content digest Image is pinned to, empty if it is not pinned
+	Entrypoint           string            // overrides Image entrypoint (or executable) if not empty
+	Command              string            // first argument passed to entrypoint
+	Args                 []string          // rest of arguments passed to entrypoint
+	WorkDirectory        string            // working directory as seen by component
+	Mounts               map[string]string // component paths mapped to host directories
+	EnvironmentVariables map[string]string
+	Stdout               io.Writer // os.Stdout if nil
+	Stderr               io.Writer // os.Stderr if nil
+	Interactive          bool      // forward stdin to job
+	Tty                  bool      // allocate pseudo-TTY for job
+}
+
+//ExitError is returned by Runtime when job finished with non-zero exit code
+type ExitError struct {
+	Code int64
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command exited with code %d", e.Code)
+}
+
+//runtimes holds registered runtimes by component type. It is modified only during package initialization
+//or with RegisterRuntime before any component is used.
+var runtimes = map[string]Runtime{}
+
+//RegisterRuntime makes runtime available for installed components of provided type
+func RegisterRuntime(componentType string, runtime Runtime) {
+	runtimes[componentType] = runtime
+}
+
+//GetRuntime returns runtime registered for component type
+func GetRuntime(componentType string) (Runtime, error) {
+	r, ok := runtimes[componentType]
+	if !ok {
+		return nil, fmt.Errorf("no runtime registered for component type \"%s\" (known types: %s)", componentType, strings.Join(RuntimeTypes(), ", "))
+	}
+	return r, nil
+}
+
+//RuntimeTypes returns sorted list of component types with registered runtime
+func RuntimeTypes() []string {
+	var types []string
+	for t := range runtimes {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+//hostPath translates path used by component to path of host directory if it points into one of mounts.
+//The most specific mount wins.
+func hostPath(mounts map[string]string, p string) (string, bool) {
+	result, matched := "", ""
+	for target, source := range mounts {
+		t := path.Clean(target)
+		if p != t && !strings.HasPrefix(p, strings.TrimSuffix(t, "/")+"/") {
+			continue
+		}
+		if len(t) > len(matched) {
+			matched = t
+			result = path.Join(source, strings.TrimPrefix(p, t))
+		}
+	}
+	return result, matched != ""
+}
@@ -0,0 +1,106 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/pkg/docker"
+)
+
+func init() {
+	RegisterRuntime("docker", dockerRuntime{})
+}
+
+//dockerRuntime runs component commands in docker containers created from component image
+type dockerRuntime struct{}
+
+//Download pulls image of component version if it is not present yet. Image of component version which is not
+//pinned yet gets pinned to content digest of pulled image, already pinned image is pulled by its digest.
+func (dockerRuntime) Download(ctx context.Context, cv *InstalledComponentVersion) error {
+	//TODO add tests
+	name, err := pinnedImage(cv.Image, cv.Digest)
+	if err != nil {
+		return err
+	}
+	dockerImage := &docker.Image{Name: name}
+	found, err := dockerImage.IsPulled(ctx)
+	if err != nil {
+		return err
+	}
+	if found {
+		logger.Debug().Msg("image is already present, no need to download") //TODO consider --force-download switch
+	} else {
+		logs, err := dockerImage.Pull(ctx)
+		errLogs := cv.PersistLogs(logs)
+		if err != nil {
+			return err
+		}
+		if errLogs != nil {
+			return fmt.Errorf("unable to persist logs of image pull: %w", errLogs)
+		}
+	}
+	if cv.Digest == "" {
+		digest, err := dockerImage.LocalDigest(ctx)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("image of component %s cannot be pinned and it will be run by tag %s", cv.Name, cv.Image)
+			return nil
+		}
+		logger.Debug().Msgf("image %s of component %s pinned to %s", cv.Image, cv.Name, digest)
+		cv.Digest = digest
+	}
+	return nil
+}
+
+//VerifyImage checks digests image tag of component version points to in docker daemon and in registry
+func (dockerRuntime) VerifyImage(ctx context.Context, cv *InstalledComponentVersion) ImageVerification {
+	v := ImageVerification{Component: cv.Name, Version: cv.Version, Image: cv.Image, Pinned: cv.Digest}
+	dockerImage := &docker.Image{Name: cv.Image}
+	var err error
+	v.Local, err = dockerImage.LocalDigest(ctx)
+	if err != nil {
+		v.Errors = append(v.Errors, fmt.Sprintf("local: %v", err))
+	}
+	v.Registry, err = dockerImage.RemoteDigest(ctx)
+	if err != nil {
+		v.Errors = append(v.Errors, fmt.Sprintf("registry: %v", err))
+	}
+	return v
+}
+
+//Run executes job in new docker container
+func (dockerRuntime) Run(ctx context.Context, job Job) error {
+	image, err := pinnedImage(job.Image, job.Digest)
+	if err != nil {
+		return err
+	}
+	dockerJob := &docker.Job{
+		Image:                image,
+		Entrypoint:           job.Entrypoint,
+		Command:              job.Command,
+		Args:                 job.Args,
+		WorkDirectory:        job.WorkDirectory,
+		Mounts:               job.Mounts,
+		EnvironmentVariables: job.EnvironmentVariables,
+		Stdout:               job.Stdout,
+		Stderr:               job.Stderr,
+		Interactive:          job.Interactive,
+		Tty:                  job.Tty,
+	}
+	logger.Debug().Msgf("will try to run docker job %+v", dockerJob)
+	err = dockerJob.Run(ctx)
+	var exitErr *docker.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: exitErr.Code}
+	}
+	return err
+}
+
+//pinnedImage returns reference to image by digest or image itself if digest is empty
+func pinnedImage(image, digest string) (string, error) {
+	if digest == "" {
+		return image, nil
+	}
+	return docker.PinnedName(image, digest)
+}
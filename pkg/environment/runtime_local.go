@@ -0,0 +1,108 @@
+package environment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+)
+
+//localStopGracePeriod is time given to local process to finish after job was cancelled before it gets killed
+const localStopGracePeriod = 10 * time.Second
+
+func init() {
+	RegisterRuntime("local", localRuntime{})
+}
+
+//localRuntime runs component commands as host processes. Image of component is name or path of executable
+//and works like docker image entrypoint. As there is no container, arguments, environment variable values and
+//working directory pointing into component mounts are translated to paths of host directories backing these mounts.
+type localRuntime struct{}
+
+//Download checks if executable of component version is available on host
+func (localRuntime) Download(_ context.Context, cv *InstalledComponentVersion) error {
+	_, err := exec.LookPath(cv.Image)
+	if err != nil {
+		return fmt.Errorf("executable %s of component %s not found: %w", cv.Image, cv.Name, err)
+	}
+	return nil
+}
+
+//Run executes job as host process
+func (localRuntime) Run(ctx context.Context, job Job) error {
+	executable := job.Image
+	if job.Entrypoint != "" {
+		executable = job.Entrypoint
+	}
+	var args []string
+	if job.Command != "" {
+		args = append(args, job.Command)
+	}
+	args = append(args, job.Args...)
+	for i, a := range args {
+		if p, ok := hostPath(job.Mounts, a); ok {
+			args[i] = p
+		}
+	}
+
+	cmd := exec.Command(executable, args...)
+	cmd.Env = os.Environ()
+	for k, v := range job.EnvironmentVariables {
+		if p, ok := hostPath(job.Mounts, v); ok {
+			v = p
+		}
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	if job.WorkDirectory != "" {
+		if p, ok := hostPath(job.Mounts, job.WorkDirectory); ok {
+			cmd.Dir = p
+		} else {
+			logger.Debug().Msgf("work directory %s is not in any mount, current directory will be used", job.WorkDirectory)
+		}
+	}
+	cmd.Stdout, cmd.Stderr = job.Stdout, job.Stderr
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	if job.Interactive {
+		cmd.Stdin = os.Stdin
+	}
+
+	logger.Debug().Msgf("will try to run local process %s %q in %s", executable, args, cmd.Dir)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		logger.Warn().Msgf("job cancelled, stopping process %d", cmd.Process.Pid)
+		if cmd.Process.Signal(os.Interrupt) != nil {
+			_ = cmd.Process.Kill()
+		}
+		select {
+		case <-done:
+		case <-time.After(localStopGracePeriod):
+			_ = cmd.Process.Kill()
+			<-done
+		}
+		return ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &ExitError{Code: int64(exitErr.ExitCode())}
+	}
+	return err
+}
@@ -0,0 +1,387 @@
+package environment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRuntime(t *testing.T) {
+	tests := []struct {
+		name          string
+		componentType string
+		wantErr       error
+	}{
+		{
+			name:          "docker",
+			componentType: "docker",
+		},
+		{
+			name:          "local",
+			componentType: "local",
+		},
+		{
+			name:          "unknown",
+			componentType: "unknown",
+			wantErr:       errors.New("no runtime registered for component type \"unknown\" (known types: docker, local)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := GetRuntime(tt.componentType)
+			if tt.wantErr != nil {
+				a.EqualError(err, tt.wantErr.Error())
+				return
+			}
+			a.NoError(err)
+			a.NotNil(got)
+		})
+	}
+}
+
+func TestHostPath(t *testing.T) {
+	mounts := map[string]string{
+		"/shared":      "/host/env/shared",
+		"/data":        "/host/env/c1/v1/mounts/data",
+		"/data/nested": "/host/other",
+	}
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOk bool
+	}{
+		{
+			name:   "mount root",
+			path:   "/shared",
+			want:   "/host/env/shared",
+			wantOk: true,
+		},
+		{
+			name:   "file in mount",
+			path:   "/data/state.json",
+			want:   "/host/env/c1/v1/mounts/data/state.json",
+			wantOk: true,
+		},
+		{
+			name:   "most specific mount",
+			path:   "/data/nested/file",
+			want:   "/host/other/file",
+			wantOk: true,
+		},
+		{
+			name:   "similar prefix",
+			path:   "/database",
+			wantOk: false,
+		},
+		{
+			name:   "not a path",
+			path:   "apply",
+			wantOk: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, ok := hostPath(mounts, tt.path)
+			a.Equal(tt.wantOk, ok)
+			a.Equal(tt.want, got)
+		})
+	}
+}
+
+func TestLocalRuntime_Run(t *testing.T) {
+	hostDirectory, err := ioutil.TempDir("", "local-runtime-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(hostDirectory)
+	}()
+
+	tests := []struct {
+		name       string
+		job        Job
+		timeout    time.Duration
+		wantStdout string
+		wantErr    error
+	}{
+		{
+			name: "mounts and envs translated",
+			job: Job{
+				Image:                "sh",
+				Command:              "-c",
+				Args:                 []string{`echo "$GREETING" > "$1" && pwd && echo "$TARGET"`, "sh", "/data/out.txt"},
+				WorkDirectory:        "/data",
+				Mounts:               map[string]string{"/data": hostDirectory},
+				EnvironmentVariables: map[string]string{"GREETING": "hello", "TARGET": "/data/target"},
+			},
+			wantStdout: hostDirectory + "\n" + path.Join(hostDirectory, "target") + "\n",
+		},
+		{
+			name: "exit code",
+			job: Job{
+				Image:   "sh",
+				Command: "-c",
+				Args:    []string{"exit 3"},
+			},
+			wantErr: &ExitError{Code: 3},
+		},
+		{
+			name: "cancelled",
+			job: Job{
+				Image:   "sh",
+				Command: "-c",
+				Args:    []string{"exec sleep 5"},
+			},
+			timeout: 100 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			ctx := context.Background()
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+				defer cancel()
+			}
+			stdout := &bytes.Buffer{}
+			tt.job.Stdout = stdout
+			tt.job.Stderr = &bytes.Buffer{}
+			err := localRuntime{}.Run(ctx, tt.job)
+			if tt.wantErr != nil {
+				a.Equal(tt.wantErr, err)
+				return
+			}
+			a.NoError(err)
+			a.Equal(tt.wantStdout, stdout.String())
+			a.FileExists(path.Join(hostDirectory, "out.txt"))
+		})
+	}
+}
+
+func TestInstalledComponentVersion_Run(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "run")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	cv := &InstalledComponentVersion{
+		EnvironmentRef: uuid.MustParse("3e5b7269-1b3d-4003-9454-9f472857633a"),
+		Name:           "c1",
+		Type:           "local",
+		Version:        "v1",
+		Image:          "true",
+		Mounts:         []string{"/data"},
+		Commands: []InstalledComponentCommand{
+			{
+				Name:    "ok",
+				Command: "ok",
+			},
+		},
+	}
+
+	a.NoError(cv.Download(context.Background()))
+	a.NoError(cv.Run(context.Background(), "ok", func(s string) (string, error) { return s, nil }, RunOptions{}))
+	a.EqualError(cv.Run(context.Background(), "missing", func(s string) (string, error) { return s, nil }, RunOptions{}), "component c1 has no command missing")
+	a.DirExists(path.Join(util.UsedEnvironmentDirectory, cv.EnvironmentRef.String(), "c1", "v1", util.DefaultComponentMountsSubdirectory, "data"))
+
+	r, err := cv.GetRun("")
+	a.NoError(err)
+	a.Equal("ok", r.Name)
+	a.Equal(int64(0), r.ExitCode)
+}
+
+func TestInstalledComponentVersion_Run_ArgsAndEnvs(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "run-args")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+
+	processor := func(s string) (string, error) {
+		return strings.ReplaceAll(s, "{{ .Value }}", "processed"), nil
+	}
+	tests := []struct {
+		name       string
+		options    RunOptions
+		wantArgs   []string
+		wantStdout string
+	}{
+		{
+			name:       "declared only",
+			options:    RunOptions{},
+			wantArgs:   []string{`printf '%s|' "$@" "$FOO"`, "sh", "processed"},
+			wantStdout: "processed|declared|",
+		},
+		{
+			name:       "extra args and env override",
+			options:    RunOptions{ExtraArgs: []string{"-target=module.x", "{{ .Value }}"}, Envs: map[string]string{"FOO": "overridden"}},
+			wantArgs:   []string{`printf '%s|' "$@" "$FOO"`, "sh", "processed", "-target=module.x", "{{ .Value }}"},
+			wantStdout: "processed|-target=module.x|{{ .Value }}|overridden|",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			cv := &InstalledComponentVersion{
+				EnvironmentRef: uuid.New(),
+				Name:           "c1",
+				Type:           "local",
+				Version:        "v1",
+				Image:          "sh",
+				Commands: []InstalledComponentCommand{
+					{
+						Name:    "print",
+						Command: "-c",
+						Args:    []string{`printf '%s|' "$@" "$FOO"`, "sh", "{{ .Value }}"},
+						Envs:    map[string]string{"FOO": "declared"},
+					},
+				},
+			}
+			a.NoError(cv.Run(context.Background(), "print", processor, tt.options))
+
+			r, err := cv.GetRun("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			a.Equal(tt.wantArgs, r.Args)
+			stdout, _, err := r.Output()
+			a.NoError(err)
+			a.Equal(tt.wantStdout, stdout)
+		})
+	}
+}
+
+func TestImageVerification_Status(t *testing.T) {
+	tests := []struct {
+		name         string
+		verification ImageVerification
+		want         string
+	}{
+		{
+			name:         "not pinned",
+			verification: ImageVerification{Local: "sha256:a", Registry: "sha256:a"},
+			want:         VerificationNotPinned,
+		},
+		{
+			name:         "ok",
+			verification: ImageVerification{Pinned: "sha256:a", Local: "sha256:a", Registry: "sha256:a"},
+			want:         VerificationOk,
+		},
+		{
+			name:         "ok without registry",
+			verification: ImageVerification{Pinned: "sha256:a", Local: "sha256:a"},
+			want:         VerificationOk,
+		},
+		{
+			name:         "local drift",
+			verification: ImageVerification{Pinned: "sha256:a", Local: "sha256:b", Registry: "sha256:a"},
+			want:         VerificationDrift,
+		},
+		{
+			name:         "registry drift",
+			verification: ImageVerification{Pinned: "sha256:a", Registry: "sha256:b"},
+			want:         VerificationDrift,
+		},
+		{
+			name:         "unknown",
+			verification: ImageVerification{Pinned: "sha256:a"},
+			want:         VerificationUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			a.Equal(tt.want, tt.verification.Status())
+		})
+	}
+}
+
+//pinningRuntime pins images to digest on download and reports current digest of every image
+type pinningRuntime struct {
+	localRuntime
+	pinned  string
+	current string
+}
+
+func (r pinningRuntime) Download(_ context.Context, cv *InstalledComponentVersion) error {
+	cv.Digest = r.pinned
+	return nil
+}
+
+func (r pinningRuntime) VerifyImage(_ context.Context, cv *InstalledComponentVersion) ImageVerification {
+	return ImageVerification{Component: cv.Name, Version: cv.Version, Image: cv.Image, Pinned: cv.Digest, Local: r.current}
+}
+
+//pullingRuntime persists pull logs and pins images on download as docker runtime does, download fails with err if set
+type pullingRuntime struct {
+	localRuntime
+	pinned string
+	err    error
+}
+
+func (r pullingRuntime) Download(_ context.Context, cv *InstalledComponentVersion) error {
+	err := cv.PersistLogs("pulled " + cv.Image)
+	if err != nil {
+		return err
+	}
+	if r.err != nil {
+		return r.err
+	}
+	cv.Digest = r.pinned
+	return nil
+}
+
+func TestEnvironment_Verify(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "env-verify")
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	runtimes["pinning"] = pinningRuntime{pinned: "sha256:a", current: "sha256:a"}
+	defer delete(runtimes, "pinning")
+	a := assert.New(t)
+
+	e, err := Create("verify")
+	a.NoError(err)
+	a.NoError(e.Install(context.Background(), InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "c1", Type: "pinning", Version: "v1", Image: "org/c1:v1"}))
+	a.NoError(e.Install(context.Background(), InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "c2", Type: "local", Version: "v1", Image: "sh"}))
+	saved, err := Get(e.Uuid)
+	a.NoError(err)
+	a.Equal("sha256:a", saved.Installed[0].Digest)
+
+	got, err := e.Verify(context.Background(), "")
+	a.NoError(err)
+	if a.Len(got, 1) {
+		a.Equal("c1", got[0].Component)
+		a.Equal(VerificationOk, got[0].Status())
+	}
+
+	runtimes["pinning"] = pinningRuntime{current: "sha256:b"}
+	got, err = e.Verify(context.Background(), "c1")
+	a.NoError(err)
+	if a.Len(got, 1) {
+		a.Equal(VerificationDrift, got[0].Status())
+	}
+
+	got, err = e.Verify(context.Background(), "c2")
+	a.NoError(err)
+	a.Empty(got)
+
+	_, err = e.Verify(context.Background(), "missing")
+	a.EqualError(err, "no such component installed")
+}
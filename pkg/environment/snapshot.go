@@ -0,0 +1,298 @@
+package environment
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/epiphany-platform/cli/internal/logger"
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/google/uuid"
+	"github.com/mholt/archiver/v3"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	snapshotArchiveExtension  = ".zip"
+	snapshotMetadataExtension = ".yaml"
+
+	//AutoSnapshotsKept is number of the newest automatic snapshots kept for environment, older ones are deleted
+	AutoSnapshotsKept = 10
+)
+
+//Snapshot holds metadata of archived copy of whole environment directory (config, mounts, shared directory, keys
+//and runs). Snapshots of environment are numbered with increasing Version.
+type Snapshot struct {
+	Version     int       `yaml:"version"`
+	Environment uuid.UUID `yaml:"environment"`
+	Name        string    `yaml:"name"` // name of environment when snapshot was taken
+	Created     time.Time `yaml:"created"`
+	Description string    `yaml:"description,omitempty"`
+	Auto        bool      `yaml:"auto,omitempty"` // taken automatically before run of component command
+	Size        int64     `yaml:"size"`           // size of archive in bytes
+	Hash        string    `yaml:"hash"`           // sha256 of archive verified before restore
+}
+
+//snapshotsDirectory returns directory with snapshots of environment
+func snapshotsDirectory(environment uuid.UUID) string {
+	return path.Join(util.UsedSnapshotsDirectory, environment.String())
+}
+
+func (s *Snapshot) archivePath() string {
+	return path.Join(snapshotsDirectory(s.Environment), strconv.Itoa(s.Version)+snapshotArchiveExtension)
+}
+
+func (s *Snapshot) metadataPath() string {
+	return path.Join(snapshotsDirectory(s.Environment), strconv.Itoa(s.Version)+snapshotMetadataExtension)
+}
+
+//CreateSnapshot archives current state of environment directory into snapshots store. Automatic snapshots above
+//AutoSnapshotsKept are deleted starting with the oldest one.
+func (e *Environment) CreateSnapshot(description string, auto bool) (*Snapshot, error) {
+	snapshots, err := e.Snapshots()
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{
+		Version:     1,
+		Environment: e.Uuid,
+		Name:        e.Name,
+		Created:     time.Now().UTC().Truncate(time.Second),
+		Description: description,
+		Auto:        auto,
+	}
+	if len(snapshots) > 0 {
+		s.Version = snapshots[len(snapshots)-1].Version + 1
+	}
+	util.EnsureDirectory(snapshotsDirectory(e.Uuid))
+
+	logger.Debug().Msgf("will try to create snapshot %d of environment %s", s.Version, e.Uuid.String())
+	tempDir, err := e.copyDirectory(true)
+	defer func() {
+		_ = os.RemoveAll(tempDir)
+	}()
+	if err != nil {
+		return nil, err
+	}
+	envTempPath := path.Join(tempDir, e.Uuid.String())
+	// archive is created next to temporary copy first, so that store never contains partially written archive
+	tempArchive := envTempPath + snapshotArchiveExtension
+	err = archiver.Archive([]string{envTempPath}, tempArchive)
+	if err != nil {
+		return nil, err
+	}
+	s.Size, s.Hash, err = hashFile(tempArchive)
+	if err != nil {
+		return nil, err
+	}
+	err = os.Rename(tempArchive, s.archivePath())
+	if err != nil {
+		return nil, err
+	}
+	err = s.save()
+	if err != nil {
+		_ = os.Remove(s.archivePath())
+		return nil, err
+	}
+
+	if auto {
+		var autos []*Snapshot
+		for _, p := range append(snapshots, s) {
+			if p.Auto {
+				autos = append(autos, p)
+			}
+		}
+		for i := 0; i < len(autos)-AutoSnapshotsKept; i++ {
+			logger.Debug().Msgf("will delete old automatic snapshot %d", autos[i].Version)
+			err = autos[i].delete()
+			if err != nil {
+				logger.Warn().Err(err).Msgf("unable to delete old automatic snapshot %d", autos[i].Version)
+			}
+		}
+	}
+	return s, nil
+}
+
+//Snapshots returns snapshots of environment ordered by version
+func (e *Environment) Snapshots() ([]*Snapshot, error) {
+	items, err := ioutil.ReadDir(snapshotsDirectory(e.Uuid))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []*Snapshot
+	for _, i := range items {
+		version, err := strconv.Atoi(strings.TrimSuffix(i.Name(), snapshotMetadataExtension))
+		if i.IsDir() || !strings.HasSuffix(i.Name(), snapshotMetadataExtension) || err != nil {
+			continue
+		}
+		s, err := getSnapshot(e.Uuid, version)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("snapshot %s is not valid and is skipped", i.Name())
+			continue
+		}
+		snapshots = append(snapshots, s)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Version < snapshots[j].Version
+	})
+	return snapshots, nil
+}
+
+//GetSnapshot returns snapshot of environment with provided version
+func (e *Environment) GetSnapshot(version int) (*Snapshot, error) {
+	return getSnapshot(e.Uuid, version)
+}
+
+func getSnapshot(environment uuid.UUID, version int) (*Snapshot, error) {
+	s := &Snapshot{Environment: environment, Version: version}
+	b, err := ioutil.ReadFile(s.metadataPath())
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("snapshot %d of environment %s not found", version, environment.String())
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(b, s)
+	if err != nil {
+		return nil, err
+	}
+	if s.Environment != environment || s.Version != version {
+		return nil, fmt.Errorf("snapshot metadata %s does not match its location", s.metadataPath())
+	}
+	return s, nil
+}
+
+//RestoreSnapshot replaces environment directory with content of snapshot with provided version. Current state of
+//environment is saved as new snapshot first. Directory is replaced only after snapshot was successfully extracted,
+//so failed restore leaves environment untouched. Restored environment is returned.
+func (e *Environment) RestoreSnapshot(version int) (*Environment, error) {
+	s, err := e.GetSnapshot(version)
+	if err != nil {
+		return nil, err
+	}
+	_, hash, err := hashFile(s.archivePath())
+	if err != nil {
+		return nil, err
+	}
+	if hash != s.Hash {
+		return nil, fmt.Errorf("archive of snapshot %d is corrupted (expected hash %s, got %s)", version, s.Hash, hash)
+	}
+
+	staging, err := ioutil.TempDir(util.UsedTempDirectory, "restore-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = os.RemoveAll(staging)
+	}()
+	logger.Debug().Msgf("will try to extract snapshot %d to %s", version, staging)
+	err = archiver.Unarchive(s.archivePath(), staging)
+	if err != nil {
+		return nil, err
+	}
+	restored := path.Join(staging, e.Uuid.String())
+	if _, err = os.Stat(path.Join(restored, util.DefaultEnvironmentConfigFileName)); err != nil {
+		return nil, fmt.Errorf("snapshot %d does not contain environment config: %w", version, err)
+	}
+
+	current, err := e.CreateSnapshot(fmt.Sprintf("before restore of snapshot %d", version), false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to snapshot current state before restore: %w", err)
+	}
+	logger.Info().Msgf("current state of environment saved as snapshot %d", current.Version)
+
+	err = replaceDirectory(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()), restored, path.Join(staging, "previous"))
+	if err != nil {
+		return nil, err
+	}
+	return Get(e.Uuid)
+}
+
+//replaceDirectory moves replacement directory in place of directory. Original directory is moved to backup path
+//first and moved back if replacement cannot be moved.
+func replaceDirectory(directory, replacement, backup string) error {
+	err := os.Rename(directory, backup)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	err = os.Rename(replacement, directory)
+	if err != nil {
+		if err2 := os.Rename(backup, directory); err2 != nil {
+			logger.Error().Err(err2).Msgf("unable to move directory back from %s", backup)
+		}
+		return err
+	}
+	return nil
+}
+
+//restoreDirectory brings back directory moved to backup path by replaceDirectory. If there is no backup, directory
+//did not exist before and it is removed.
+func restoreDirectory(directory, backup string) {
+	err := os.RemoveAll(directory)
+	if err != nil {
+		logger.Error().Err(err).Msgf("unable to remove directory %s", directory)
+		return
+	}
+	if _, err = os.Stat(backup); err == nil {
+		if err = os.Rename(backup, directory); err != nil {
+			logger.Error().Err(err).Msgf("unable to move directory back from %s", backup)
+		}
+	}
+}
+
+//DeleteSnapshot deletes snapshot of environment with provided version
+func (e *Environment) DeleteSnapshot(version int) error {
+	s, err := e.GetSnapshot(version)
+	if err != nil {
+		return err
+	}
+	return s.delete()
+}
+
+func (s *Snapshot) delete() error {
+	err := os.Remove(s.metadataPath())
+	if err != nil {
+		return err
+	}
+	err = os.Remove(s.archivePath())
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *Snapshot) save() error {
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.metadataPath(), data, 0644)
+}
+
+//hashFile returns size and sha256 of file content
+func hashFile(filePath string) (int64, string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return 0, "", err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
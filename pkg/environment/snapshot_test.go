@@ -0,0 +1,117 @@
+package environment
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironment_Snapshots(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "snapshot")
+	util.UsedSnapshotsDirectory = path.Join(util.UsedConfigurationDirectory, util.DefaultSnapshotsSubdirectory)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	e, err := Create("snapshot")
+	a.NoError(err)
+	sharedFile := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "shared", "state.yaml")
+	a.NoError(os.MkdirAll(path.Dir(sharedFile), 0755))
+	a.NoError(ioutil.WriteFile(sharedFile, []byte("first"), 0644))
+
+	snapshots, err := e.Snapshots()
+	a.NoError(err)
+	a.Empty(snapshots)
+
+	s, err := e.CreateSnapshot("initial", false)
+	a.NoError(err)
+	a.Equal(1, s.Version)
+	a.Equal("snapshot", s.Name)
+	a.Regexp("^sha256:[0-9a-f]{64}$", s.Hash)
+	a.FileExists(s.archivePath())
+
+	a.NoError(ioutil.WriteFile(sharedFile, []byte("second"), 0644))
+	e.Name = "renamed"
+	a.NoError(e.Save())
+
+	restored, err := e.RestoreSnapshot(1)
+	a.NoError(err)
+	a.Equal("snapshot", restored.Name)
+	b, err := ioutil.ReadFile(sharedFile)
+	a.NoError(err)
+	a.Equal("first", string(b))
+	a.NoDirExists(path.Join(util.UsedTempDirectory, e.Uuid.String()))
+
+	snapshots, err = e.Snapshots()
+	a.NoError(err)
+	if a.Len(snapshots, 2) {
+		a.Equal("initial", snapshots[0].Description)
+		a.Equal("before restore of snapshot 1", snapshots[1].Description)
+		a.Equal("renamed", snapshots[1].Name)
+	}
+
+	a.NoError(e.DeleteSnapshot(1))
+	a.EqualError(e.DeleteSnapshot(1), "snapshot 1 of environment "+e.Uuid.String()+" not found")
+	_, err = e.RestoreSnapshot(1)
+	a.EqualError(err, "snapshot 1 of environment "+e.Uuid.String()+" not found")
+
+	s, err = e.CreateSnapshot("", false)
+	a.NoError(err)
+	a.Equal(3, s.Version)
+}
+
+func TestEnvironment_RestoreSnapshot_Corrupted(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "snapshot-corrupted")
+	util.UsedSnapshotsDirectory = path.Join(util.UsedConfigurationDirectory, util.DefaultSnapshotsSubdirectory)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	e, err := Create("snapshot")
+	a.NoError(err)
+	s, err := e.CreateSnapshot("", false)
+	a.NoError(err)
+	a.NoError(ioutil.WriteFile(s.archivePath(), []byte("corrupted"), 0644))
+
+	_, err = e.RestoreSnapshot(s.Version)
+	a.Error(err)
+	a.Contains(err.Error(), "archive of snapshot 1 is corrupted")
+	a.FileExists(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), util.DefaultEnvironmentConfigFileName))
+	snapshots, err := e.Snapshots()
+	a.NoError(err)
+	a.Len(snapshots, 1)
+}
+
+func TestEnvironment_CreateSnapshot_Auto(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedTempDirectory = setup(t, "snapshot-auto")
+	util.UsedSnapshotsDirectory = path.Join(util.UsedConfigurationDirectory, util.DefaultSnapshotsSubdirectory)
+	defer func() {
+		_ = os.RemoveAll(util.UsedConfigurationDirectory)
+	}()
+	a := assert.New(t)
+
+	e, err := Create("snapshot")
+	a.NoError(err)
+	_, err = e.CreateSnapshot("manual", false)
+	a.NoError(err)
+	for i := 0; i < AutoSnapshotsKept+2; i++ {
+		_, err = e.CreateSnapshot("auto", true)
+		a.NoError(err)
+	}
+
+	snapshots, err := e.Snapshots()
+	a.NoError(err)
+	if a.Len(snapshots, AutoSnapshotsKept+1) {
+		a.Equal(1, snapshots[0].Version)
+		a.False(snapshots[0].Auto)
+		a.Equal(4, snapshots[1].Version)
+		a.Equal(AutoSnapshotsKept+3, snapshots[len(snapshots)-1].Version)
+	}
+}
@@ -2,7 +2,14 @@ package processor
 
 import (
 	"bytes"
-	"strconv"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"text/template"
 
@@ -11,67 +18,167 @@ import (
 	environments "github.com/epiphany-platform/cli/pkg/environment"
 )
 
+const (
+	leftDelimiter  = "{{"
+	rightDelimiter = "}}"
+)
+
+//legacyRegexp matches deprecated "#Config#{{ .Field }}#" and "#Environment#{{ .Field }}#" segments
+var legacyRegexp = regexp.MustCompile(`#(Config|Environment)#([^#]*` + regexp.QuoteMeta(leftDelimiter) + `[^#]*)#`)
+
 func init() {
 	logger.Initialize()
 }
 
-func TemplateProcessor(config *configuration.Config, environment *environments.Environment) func(s string) string {
-	return func(s string) string {
-		if strings.Contains(s, "#") {
-			parts := strings.Split(s, "#")
-			logger.Debug().Msgf("and value has parts: %#v", parts)
-			if len(parts) < 2 {
-				return s
-			}
-			result := ""
-			for i := 0; i < len(parts); i++ {
-				switch p := parts[i]; p {
-				case "Config":
-					ii := i
-					next := i + 1
-					i++
-					r, err := process(strconv.Itoa(ii), parts[next], config)
-					if err != nil {
-						logger.Error().Err(err)
-						break
-					}
-					logger.Debug().Msgf("result value: %#v", r)
-					result = result + r
-				case "Environment":
-					ii := i
-					next := i + 1
-					i++
-					r, err := process(strconv.Itoa(ii), parts[next], environment)
-					if err != nil {
-						logger.Error().Err(err)
-						break
-					}
-					logger.Debug().Msgf("result value: %#v", r)
-					result = result + r
-				default:
-					result = result + p
-				}
-			}
-			return result
+//Data is root object available in templates processed by TemplateProcessor
+type Data struct {
+	Config      *configuration.Config
+	Environment *environments.Environment
+	Component   *environments.InstalledComponentVersion
+	Outputs     map[string]environments.Outputs // collected outputs of installed components by component name
+	Env         map[string]string
+}
+
+//TemplateProcessor returns function processing Go templates (delimited with "{{" and "}}") in component command
+//args and envs. Templates have access to Data fields and helper functions:
+// default DEFAULT VALUE - returns DEFAULT if VALUE is empty
+// required MESSAGE VALUE - fails with MESSAGE if VALUE is empty
+// base64 VALUE and base64Decode VALUE - encodes and decodes VALUE
+// env NAME - returns value of OS environment variable
+// sharedFile PATH - returns content of file from environment shared directory
+// component NAME - returns installed component of environment
+// output COMPONENT NAME - returns collected output of installed component
+//Deprecated "#Config#...#" and "#Environment#...#" segments are still understood.
+func TemplateProcessor(config *configuration.Config, environment *environments.Environment, component *environments.InstalledComponentVersion) func(s string) (string, error) {
+	data := &Data{
+		Config:      config,
+		Environment: environment,
+		Component:   component,
+		Env:         osEnv(),
+	}
+	if environment != nil {
+		data.Outputs = environment.Outputs
+	}
+	funcs := templateFuncs(environment)
+	return func(s string) (string, error) {
+		if !strings.Contains(s, leftDelimiter) {
+			return s, nil
+		}
+		pattern := translateLegacy(s)
+		r, err := process(s, pattern, data, funcs)
+		if err != nil {
+			return "", fmt.Errorf("cannot process template %q: %w", s, err)
 		}
+		return r, nil
+	}
+}
+
+//translateLegacy rewrites deprecated "#Config#...#" segments to templates scoped to Config (and the same for
+//Environment)
+func translateLegacy(s string) string {
+	if !legacyRegexp.MatchString(s) {
 		return s
 	}
+	logger.Warn().Msgf("template %q uses deprecated '#' syntax, use {{ .Config.Field }} or {{ .Environment.Field }} instead", s)
+	return legacyRegexp.ReplaceAllString(s, leftDelimiter+" with .$1 "+rightDelimiter+"$2"+leftDelimiter+" end "+rightDelimiter)
 }
 
-func process(name, pattern string, data interface{}) (string, error) {
-	t, err := template.New(name).Option("missingkey=error").Parse(pattern)
+func process(name, pattern string, data interface{}, funcs template.FuncMap) (string, error) {
+	t, err := template.New(name).
+		Delims(leftDelimiter, rightDelimiter).
+		Funcs(funcs).
+		Option("missingkey=error").
+		Parse(pattern)
 	if err != nil {
-		logger.Error().Err(err)
 		return "", err
 	}
 	logger.Debug().Msgf("parsed template: %#v", t)
 	var b bytes.Buffer
 	err = t.Execute(&b, data)
 	if err != nil {
-		logger.Error().Err(err)
 		return "", err
 	}
 	r := b.String()
 	logger.Debug().Msgf("result value: %#v", r)
 	return r, nil
 }
+
+//templateFuncs returns helper functions available in templates. Functions related to environment fail if
+//environment is nil.
+func templateFuncs(environment *environments.Environment) template.FuncMap {
+	return template.FuncMap{
+		"default": func(d interface{}, v interface{}) interface{} {
+			if isEmpty(v) {
+				return d
+			}
+			return v
+		},
+		"required": func(message string, v interface{}) (interface{}, error) {
+			if isEmpty(v) {
+				return nil, errors.New(message)
+			}
+			return v, nil
+		},
+		"base64": func(s string) string {
+			return base64.StdEncoding.EncodeToString([]byte(s))
+		},
+		"base64Decode": func(s string) (string, error) {
+			b, err := base64.StdEncoding.DecodeString(s)
+			return string(b), err
+		},
+		"env": os.Getenv,
+		"sharedFile": func(p string) (string, error) {
+			if environment == nil {
+				return "", errors.New("no environment to read shared file from")
+			}
+			// cleaning rooted path prevents escaping shared directory
+			b, err := ioutil.ReadFile(filepath.Join(environment.SharedDirectory(), filepath.Clean("/"+p)))
+			return string(b), err
+		},
+		"component": func(name string) (*environments.InstalledComponentVersion, error) {
+			if environment == nil {
+				return nil, errors.New("no environment to get component from")
+			}
+			return environment.GetComponentByName(name)
+		},
+		"output": func(componentName, outputName string) (interface{}, error) {
+			if environment == nil {
+				return nil, errors.New("no environment to get output from")
+			}
+			values, err := environment.GetOutputs(componentName)
+			if err != nil {
+				return nil, err
+			}
+			v, ok := values[outputName]
+			if !ok {
+				return nil, fmt.Errorf("component %s has no output %s", componentName, outputName)
+			}
+			return v, nil
+		},
+	}
+}
+
+//isEmpty checks if value is nil or zero value of its type
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return rv.Len() == 0
+	}
+	return rv.IsZero()
+}
+
+//osEnv returns OS environment variables as map
+func osEnv() map[string]string {
+	result := make(map[string]string)
+	for _, e := range os.Environ() {
+		kv := strings.SplitN(e, "=", 2)
+		if len(kv) == 2 {
+			result[kv[0]] = kv[1]
+		}
+	}
+	return result
+}
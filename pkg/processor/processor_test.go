@@ -1,7 +1,17 @@
 package processor
 
 import (
+	"io/ioutil"
+	"os"
+	"path"
 	"testing"
+
+	"github.com/epiphany-platform/cli/internal/util"
+	"github.com/epiphany-platform/cli/pkg/configuration"
+	environments "github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_process(t *testing.T) {
@@ -72,7 +82,7 @@ func Test_process(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := process(tt.args.name, tt.args.pattern, tt.args.data)
+			got, err := process(tt.args.name, tt.args.pattern, tt.args.data, templateFuncs(nil))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("process() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -83,3 +93,121 @@ func Test_process(t *testing.T) {
 		})
 	}
 }
+
+func TestTemplateProcessor(t *testing.T) {
+	var err error
+	util.UsedEnvironmentDirectory, err = ioutil.TempDir("", "e-processor-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = os.RemoveAll(util.UsedEnvironmentDirectory)
+	}()
+	environment := &environments.Environment{
+		Name: "e1",
+		Uuid: uuid.MustParse("3e5b7269-1b3d-4003-9454-9f472857633a"),
+		Installed: []environments.InstalledComponentVersion{
+			{Name: "c1", Version: "v1", Image: "i1"},
+			{Name: "c2", Version: "v2", Image: "i2"},
+		},
+		Outputs: map[string]environments.Outputs{
+			"c2": {"ip": "10.0.0.1"},
+		},
+	}
+	util.EnsureDirectory(environment.SharedDirectory())
+	err = ioutil.WriteFile(path.Join(environment.SharedDirectory(), "token"), []byte("secret"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Setenv("E_PROCESSOR_TEST", "from-os")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &configuration.Config{
+		Version:            "v1",
+		CurrentEnvironment: environment.Uuid,
+	}
+	p := TemplateProcessor(config, environment, &environment.Installed[0])
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "no template",
+			value: "value#with#hashes",
+			want:  "value#with#hashes",
+		},
+		{
+			name:  "combined lookups",
+			value: "{{ .Environment.Name }}-{{ .Component.Name }}#{{ .Config.CurrentEnvironment }}",
+			want:  "e1-c1#3e5b7269-1b3d-4003-9454-9f472857633a",
+		},
+		{
+			name:  "os env",
+			value: "{{ .Env.E_PROCESSOR_TEST }} {{ env \"E_PROCESSOR_TEST\" }}",
+			want:  "from-os from-os",
+		},
+		{
+			name:  "default",
+			value: "{{ env \"E_PROCESSOR_MISSING\" | default \"fallback\" }}",
+			want:  "fallback",
+		},
+		{
+			name:    "required",
+			value:   "{{ env \"E_PROCESSOR_MISSING\" | required \"E_PROCESSOR_MISSING has to be set\" }}",
+			wantErr: "error calling required: E_PROCESSOR_MISSING has to be set",
+		},
+		{
+			name:  "base64",
+			value: "{{ base64 \"user:pass\" }} {{ base64Decode \"dXNlcjpwYXNz\" }}",
+			want:  "dXNlcjpwYXNz user:pass",
+		},
+		{
+			name:  "shared file",
+			value: "{{ sharedFile \"token\" }} {{ sharedFile \"../../token\" }}",
+			want:  "secret secret",
+		},
+		{
+			name:  "other component",
+			value: "{{ (component \"c2\").Image }}",
+			want:  "i2",
+		},
+		{
+			name:  "outputs",
+			value: "{{ output \"c2\" \"ip\" }} {{ .Outputs.c2.ip }}",
+			want:  "10.0.0.1 10.0.0.1",
+		},
+		{
+			name:    "missing output",
+			value:   "{{ output \"c2\" \"missing\" }}",
+			wantErr: "component c2 has no output missing",
+		},
+		{
+			name:  "legacy syntax",
+			value: "#Environment#{{ .Name }}#/#Config#{{ .Version }}#",
+			want:  "e1/v1",
+		},
+		{
+			name:    "unknown field",
+			value:   "{{ .Unknown }}",
+			wantErr: "can't evaluate field Unknown in type *processor.Data",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := assert.New(t)
+			got, err := p(tt.value)
+			if tt.wantErr != "" {
+				if a.Error(err) {
+					a.Contains(err.Error(), tt.wantErr)
+				}
+				return
+			}
+			a.NoError(err)
+			a.Equal(tt.want, got)
+		})
+	}
+}
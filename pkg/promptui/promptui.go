@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/epiphany-platform/cli/pkg/configuration"
 	"github.com/epiphany-platform/cli/pkg/environment"
@@ -34,6 +35,36 @@ func PromptForString(label string) (string, error) {
 	return result, nil
 }
 
+//PromptForPassword asks for masked input, if confirm is set user has to enter it twice
+func PromptForPassword(label string, confirm bool) (string, error) {
+	prompt := promptui.Prompt{
+		Label: label,
+		Mask:  '*',
+		Validate: func(input string) error {
+			if len(input) < 1 {
+				return errors.New("too short")
+			}
+			return nil
+		},
+	}
+	result, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+	if !confirm {
+		return result, nil
+	}
+	prompt.Label = "Repeat " + strings.ToLower(label[:1]) + label[1:]
+	repeated, err := prompt.Run()
+	if err != nil {
+		return "", err
+	}
+	if repeated != result {
+		return "", errors.New("entered values do not match")
+	}
+	return result, nil
+}
+
 func PromptForEnvironmentSelect(label string) (uuid.UUID, error) {
 	//TODO fix it not to call config and environments here
 	config, err := configuration.GetConfig()